@@ -0,0 +1,117 @@
+package finance
+
+//go:generate go run gen_banks.go
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrBBANInvalidChecksum is the error returned when the check digits of a Belgian bank account number are wrong
+	ErrBBANInvalidChecksum = errors.New("Bank account number has invalid check digits")
+
+	// ErrBBANUnknownBank is the error returned when the protocol code of a Belgian bank account number isn't known
+	ErrBBANUnknownBank = errors.New("Bank account number has an unknown protocol code")
+)
+
+// belgianBank contains the information the National Bank of Belgium publishes about a range of protocol codes
+type belgianBank struct {
+	From     int    // The first protocol code of the range
+	To       int    // The last protocol code of the range
+	BIC      string // The Bank Identification Code
+	BankName string // The name of the bank
+}
+
+// ConvertBBAN converts a Belgian bank account number to its IBAN and BIC without using the network
+//
+// It returns the same information as CheckIBAN: the IBAN and BIC are formatted the way the IBANBIC service formats
+// them. The bank account number may contain dashes, dots and spaces, e.g. 738-1202561-74.
+func ConvertBBAN(number string) (*IBANBICInfo, error) {
+
+	bban, err := normalizeBBAN(number)
+	if err != nil {
+		return nil, err
+	}
+
+	bank, ok := lookupBelgianBank(bban)
+	if !ok {
+		return nil, errors.Wrap(ErrBBANUnknownBank, bban[0:3])
+	}
+
+	return &IBANBICInfo{
+		BBAN:     number,
+		BankName: bank.BankName,
+		IBAN:     FormatIBAN(belgianIBAN(bban)),
+		BIC:      formatBIC(bank.BIC),
+	}, nil
+
+}
+
+// ValidateBBAN checks the structure and the check digits of a Belgian bank account number
+func ValidateBBAN(number string) error {
+	_, err := normalizeBBAN(number)
+	return err
+}
+
+// normalizeBBAN strips the separators from a Belgian bank account number and validates the check digits
+func normalizeBBAN(number string) (string, error) {
+
+	if len(number) == 0 {
+		return "", ErrIBANBICInvalidInput
+	}
+
+	bban := strings.NewReplacer("-", "", ".", "", " ", "", "/", "").Replace(number)
+	if len(bban) != 12 || !isDigits(bban) {
+		return "", ErrIBANBICInvalidInput
+	}
+
+	base, _ := strconv.ParseInt(bban[0:10], 10, 64)
+	check, _ := strconv.ParseInt(bban[10:12], 10, 64)
+
+	if check != belgianCheckDigits(base) {
+		return "", ErrBBANInvalidChecksum
+	}
+
+	return bban, nil
+
+}
+
+// lookupBelgianBank finds the bank which issued a Belgian bank account number
+func lookupBelgianBank(bban string) (belgianBank, bool) {
+	code, _ := strconv.Atoi(bban[0:3])
+	for _, bank := range belgianBanks {
+		if code >= bank.From && code <= bank.To {
+			return bank, true
+		}
+	}
+	return belgianBank{}, false
+}
+
+// belgianIBAN computes the IBAN of a normalized Belgian bank account number
+func belgianIBAN(bban string) string {
+	check := 98 - ibanMod97("BE00"+bban)
+	return "BE" + leftPad(strconv.Itoa(check), 2, '0') + bban
+}
+
+// formatBIC formats a BIC the way the IBANBIC service does, e.g. KRED BE BB
+func formatBIC(bic string) string {
+	if len(bic) < 8 {
+		return bic
+	}
+	parts := []string{bic[0:4], bic[4:6], bic[6:8]}
+	if len(bic) > 8 {
+		parts = append(parts, bic[8:])
+	}
+	return strings.Join(parts, " ")
+}
+
+// leftPad pads a string on the left up to the given length
+func leftPad(value string, length int, pad byte) string {
+	for len(value) < length {
+		value = string(pad) + value
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package finance
+
+// belgianBanks contains the protocol code ranges of the National Bank of Belgium, sorted by protocol code
+//
+// The protocol code consists of the first three digits of a Belgian bank account number. These ranges only cover the
+// largest banks, run go generate to replace them with the full list published by the NBB.
+var belgianBanks = []belgianBank{
+	{0, 0, "BPOTBEB1", "bpost bank"},
+	{1, 49, "GEBABEBB", "BNP Paribas Fortis"},
+	{50, 99, "GKCCBEBB", "Belfius Bank"},
+	{100, 100, "NBBEBEBB203", "Nationale Bank van België"},
+	{140, 149, "GEBABEBB", "BNP Paribas Fortis"},
+	{200, 214, "GEBABEBB", "BNP Paribas Fortis"},
+	{220, 298, "GEBABEBB", "BNP Paribas Fortis"},
+	{300, 399, "BBRUBEBB", "ING België"},
+	{400, 499, "KREDBEBB", "KBC Bank"},
+	{523, 523, "TRIOBEBB", "Triodos Bank"},
+	{630, 631, "BBRUBEBB", "ING België"},
+	{645, 645, "JVBABE22", "Bank J.Van Breda en C°"},
+	{651, 651, "KEYTBEBB", "Keytrade Bank"},
+	{725, 727, "KREDBEBB", "KBC Bank"},
+	{730, 731, "KREDBEBB", "KBC Bank"},
+	{732, 732, "CREGBEBB", "CBC Banque"},
+	{733, 741, "KREDBEBB", "KBC Bank"},
+	{742, 742, "CREGBEBB", "CBC Banque"},
+	{743, 749, "KREDBEBB", "KBC Bank"},
+	{750, 774, "AXABBE22", "AXA Bank Belgium"},
+	{775, 799, "GKCCBEBB", "Belfius Bank"},
+	{890, 899, "VDSPBE91", "vdk bank"},
+	{973, 979, "ARSPBE22", "Argenta Spaarbank"},
+}
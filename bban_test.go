@@ -0,0 +1,66 @@
+package finance_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestConvertBBAN(t *testing.T) {
+
+	type test struct {
+		number           string
+		expectedBankName string
+		expectedIBAN     string
+		expectedBIC      string
+		expectedError    error
+	}
+
+	var tests = []test{
+		{"738-1202561-74", "KBC Bank", "BE16 7381 2025 6174", "KRED BE BB", nil},
+		{"738120256174", "KBC Bank", "BE16 7381 2025 6174", "KRED BE BB", nil},
+		{"7381202561-74", "KBC Bank", "BE16 7381 2025 6174", "KRED BE BB", nil},
+		{"001-2345678-03", "BNP Paribas Fortis", "", "GEBA BE BB", nil},
+		{"100-0000000-34", "Nationale Bank van België", "", "NBBE BE BB 203", nil},
+		{"738-1202561-75", "", "", "", finance.ErrBBANInvalidChecksum},
+		{"738-AAAAAAA-74", "", "", "", finance.ErrIBANBICInvalidInput},
+		{"738-AAAAAAA-AA", "", "", "", finance.ErrIBANBICInvalidInput},
+		{"73812025617", "", "", "", finance.ErrIBANBICInvalidInput},
+		{"", "", "", "", finance.ErrIBANBICInvalidInput},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.number, func(t *testing.T) {
+
+			info, err := finance.ConvertBBAN(tc.number)
+
+			if tc.expectedError != nil {
+				assert.Nil(t, info, "info")
+				assert.True(t, errors.Is(err, tc.expectedError), "expected %v, got %v", tc.expectedError, err)
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			if assert.NotNil(t, info, "info") {
+				assert.Equal(t, tc.number, info.BBAN, "bban")
+				assert.Equal(t, tc.expectedBankName, info.BankName, "bank-name")
+				assert.Equal(t, tc.expectedBIC, info.BIC, "BIC")
+				if tc.expectedIBAN != "" {
+					assert.Equal(t, tc.expectedIBAN, info.IBAN, "IBAN")
+				}
+				assert.NoError(t, finance.ValidateIBAN(info.IBAN), "valid-iban")
+			}
+
+		})
+	}
+
+}
+
+func TestValidateBBAN(t *testing.T) {
+	assert.NoError(t, finance.ValidateBBAN("738-1202561-74"))
+	assert.Equal(t, finance.ErrBBANInvalidChecksum, finance.ValidateBBAN("738-1202561-73"))
+	assert.Equal(t, finance.ErrIBANBICInvalidInput, finance.ValidateBBAN("738"))
+}
@@ -0,0 +1,298 @@
+package finance
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultHTTPClient is the HTTP client shared by all clients which don't specify their own
+var defaultHTTPClient = &http.Client{}
+
+// Client performs the requests to the ECB, VIES and IBANBIC services
+//
+// A client is safe for concurrent use. Its settings are fixed when it's created with NewClient.
+type Client struct {
+	ratesURL               string
+	historicalRatesURL     string
+	fullHistoricalRatesURL string
+	ratesTimeout           time.Duration
+
+	vatServiceURL    string
+	vatTimeout       time.Duration
+	vatPrevalidation bool
+	vatCache         VATCachePolicy
+
+	ibanbicServiceURL string
+	ibanbicTimeout    time.Duration
+
+	httpClient  *http.Client
+	userAgent   string
+	retryPolicy RetryPolicy
+}
+
+// ClientOption defines an option which can be passed to NewClient
+type ClientOption func(*Client)
+
+// NewClient returns a new client configured with the given options
+func NewClient(opts ...ClientOption) *Client {
+
+	c := &Client{
+		ratesURL:               DefaultRatesURL,
+		historicalRatesURL:     DefaultHistoricalRatesURL,
+		fullHistoricalRatesURL: DefaultFullHistoricalRatesURL,
+		ratesTimeout:           DefaultRatesTimeout,
+		vatServiceURL:          DefaultVATServiceURL,
+		vatTimeout:             DefaultVATTimeout,
+		ibanbicServiceURL:      DefaultIBANBICServiceURL,
+		ibanbicTimeout:         DefaultIBANBICTimeout,
+		httpClient:             defaultHTTPClient,
+		retryPolicy:            RetryPolicy{MaxAttempts: 1},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+
+}
+
+// WithRatesURL sets the URL where to fetch the daily exchange rates from
+func WithRatesURL(url string) ClientOption {
+	return func(c *Client) {
+		c.ratesURL = url
+	}
+}
+
+// WithHistoricalRatesURL sets the URL where to fetch the exchange rates of the last 90 days from
+func WithHistoricalRatesURL(url string) ClientOption {
+	return func(c *Client) {
+		c.historicalRatesURL = url
+	}
+}
+
+// WithFullHistoricalRatesURL sets the URL where to fetch all historical exchange rates from
+func WithFullHistoricalRatesURL(url string) ClientOption {
+	return func(c *Client) {
+		c.fullHistoricalRatesURL = url
+	}
+}
+
+// WithRatesTimeout sets the timeout to use when fetching exchange rates
+func WithRatesTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.ratesTimeout = timeout
+	}
+}
+
+// WithVATServiceURL sets the SOAP URL to use when checking a VAT number
+func WithVATServiceURL(url string) ClientOption {
+	return func(c *Client) {
+		c.vatServiceURL = url
+	}
+}
+
+// WithVATTimeout sets the timeout to use when checking a VAT number
+func WithVATTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.vatTimeout = timeout
+	}
+}
+
+// WithVATPrevalidation enables the offline validation of VAT numbers before they are sent to VIES
+//
+// When enabled, CheckVAT returns a *VATError without calling VIES for numbers which fail ValidateVAT.
+func WithVATPrevalidation(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.vatPrevalidation = enabled
+	}
+}
+
+// WithVATCache enables caching the results of CheckVAT according to the given policy
+func WithVATCache(policy VATCachePolicy) ClientOption {
+	return func(c *Client) {
+		c.vatCache = policy
+	}
+}
+
+// WithIBANBICServiceURL sets the URL to use when checking a bank account number
+func WithIBANBICServiceURL(url string) ClientOption {
+	return func(c *Client) {
+		c.ibanbicServiceURL = url
+	}
+}
+
+// WithIBANBICTimeout sets the timeout to use when checking a bank account number
+func WithIBANBICTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.ibanbicTimeout = timeout
+	}
+}
+
+// WithTimeout sets the timeout to use for all services
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.ratesTimeout = timeout
+		c.vatTimeout = timeout
+		c.ibanbicTimeout = timeout
+	}
+}
+
+// WithHTTPClient sets the HTTP client to use for performing the requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header which is sent with each request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy sets the policy to use when a request fails with a transient error
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// defaultClient returns a client which is configured using the package-level variables
+func defaultClient() *Client {
+	return NewClient(
+		WithRatesURL(RatesURL),
+		WithHistoricalRatesURL(HistoricalRatesURL),
+		WithFullHistoricalRatesURL(FullHistoricalRatesURL),
+		WithRatesTimeout(DefaultTimeout),
+		WithVATServiceURL(VATServiceURL),
+		WithVATTimeout(VATTimeout),
+		WithIBANBICServiceURL(IBANBICServiceURL),
+		WithIBANBICTimeout(IBANBICTimeout),
+	)
+}
+
+// unreachableError is the error returned when a service couldn't be reached
+type unreachableError struct {
+	err       error
+	permanent bool // Indicates that retrying the request won't help, e.g. because the URL is invalid
+}
+
+func (e *unreachableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unreachableError) Unwrap() error {
+	return e.err
+}
+
+// StatusError is the error returned when a service answers with an HTTP error status
+//
+// It's only returned once all attempts allowed by the retry policy are used up.
+type StatusError struct {
+	URL        string // The URL which was requested
+	StatusCode int    // The HTTP status code of the last response
+}
+
+// Error returns the error message
+func (e *StatusError) Error() string {
+	return "Service returned HTTP status " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode) + ": " + e.URL
+}
+
+// doRequest performs a request and returns the response body
+//
+// Requests which can't reach the service are retried according to the retry policy. When retryable is not nil,
+// it's called with the status code and body of each response to decide whether the request should be retried.
+// When all attempts are used up, the last response is returned. If it has an HTTP 4xx or 5xx status, the body is
+// returned together with a *StatusError so that callers can still interpret service specific error responses.
+func (c *Client) doRequest(ctx context.Context, timeout time.Duration, method string, url string, contentType string, body []byte, retryable func(status int, body []byte) bool) ([]byte, error) {
+
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+
+		result, status, err := c.doSingleRequest(ctx, timeout, method, url, contentType, body)
+
+		retry := false
+		if err != nil {
+			unreachable, ok := err.(*unreachableError)
+			retry = ok && !unreachable.permanent
+		} else if retryable != nil {
+			retry = retryable(status, result)
+		}
+
+		if !retry {
+			return result, statusError(url, status, err)
+		}
+
+		delay, ok := c.retryPolicy.nextDelay(attempt, time.Since(start))
+		if !ok {
+			return result, statusError(url, status, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+	}
+
+}
+
+// doSingleRequest performs a single request and returns the response body and status code
+//
+// When the parent context is cancelled or its deadline is exceeded, the context error is returned as is.
+func (c *Client) doSingleRequest(parent context.Context, timeout time.Duration, method string, url string, contentType string, body []byte) ([]byte, int, error) {
+
+	ctx := parent
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, &unreachableError{err: err, permanent: true}
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		if parent.Err() != nil {
+			return nil, 0, parent.Err()
+		}
+		return nil, 0, &unreachableError{err: err}
+	}
+	defer res.Body.Close()
+
+	result, err := ioutil.ReadAll(res.Body)
+	if err != nil && parent.Err() != nil {
+		return nil, res.StatusCode, parent.Err()
+	}
+
+	return result, res.StatusCode, err
+
+}
+
+// statusError returns a *StatusError when a successful request returned an HTTP error status
+func statusError(url string, status int, err error) error {
+	if err == nil && status >= http.StatusBadRequest {
+		return &StatusError{URL: url, StatusCode: status}
+	}
+	return err
+}
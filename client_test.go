@@ -0,0 +1,157 @@
+package finance_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestClientConcurrentSettings(t *testing.T) {
+
+	s1 := newDailyRatesServer()
+	defer s1.Close()
+
+	s2 := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("hello"))
+		}),
+	)
+	defer s2.Close()
+
+	valid := finance.NewClient(finance.WithRatesURL(s1.URL))
+	invalid := finance.NewClient(finance.WithRatesURL(s2.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rates, err := valid.ExchangeRates()
+			assert.NoError(t, err, "valid-error")
+			assert.NotEmpty(t, rates, "valid-rates")
+		}()
+		go func() {
+			defer wg.Done()
+			rates, err := invalid.ExchangeRates()
+			assert.Error(t, err, "invalid-error")
+			assert.Empty(t, rates, "invalid-rates")
+		}()
+	}
+	wg.Wait()
+
+}
+
+func TestClientUserAgent(t *testing.T) {
+
+	var userAgent string
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent = r.UserAgent()
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithUserAgent("go-finance-test/1.0"),
+	)
+
+	_, err := client.ExchangeRates()
+
+	assert.NoError(t, err, "error")
+	assert.Equal(t, "go-finance-test/1.0", userAgent, "user-agent")
+
+}
+
+func TestClientTimeout(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithVATServiceURL(s.URL),
+		finance.WithIBANBICServiceURL(s.URL),
+		finance.WithTimeout(100*time.Millisecond),
+	)
+
+	_, err := client.ExchangeRates()
+	assert.Error(t, err, "rates-error")
+
+	_, err = client.CheckVAT("BE0836157420")
+	assert.Equal(t, finance.ErrVATserviceUnreachable, err, "vat-error")
+
+	_, err = client.CheckIBAN("738120256174")
+	assert.Equal(t, finance.ErrIBANBICServiceUnreachable, err, "iban-error")
+
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithRatesTimeout(100*time.Millisecond),
+		finance.WithRetryPolicy(finance.RetryPolicy{MaxAttempts: 3, Delay: 10 * time.Millisecond}),
+	)
+
+	rates, err := client.ExchangeRates()
+
+	assert.NoError(t, err, "error")
+	assert.NotEmpty(t, rates, "rates")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestClientHTTPClient(t *testing.T) {
+
+	s := newDailyRatesServer()
+	defer s.Close()
+
+	transport := &countingTransport{}
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+
+	_, err := client.ExchangeRates()
+
+	assert.NoError(t, err, "error")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&transport.requests), "requests")
+
+}
+
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// bulkResultColumns are the columns which are appended to each row
+var bulkResultColumns = []string{"valid", "name", "address", "iban", "bic", "error"}
+
+// bulkResult is the result of checking a single row
+type bulkResult struct {
+	valid   bool
+	name    string
+	address string
+	iban    string
+	bic     string
+	err     error
+	code    int // The exit code for this row
+}
+
+// bulkChecker checks a single value
+type bulkChecker func(ctx context.Context, client *finance.Client, value string) bulkResult
+
+// bulkCheckers contains the checks which can be used in bulk mode, keyed by name
+var bulkCheckers = map[string]bulkChecker{
+	"vat":          checkVATRow,
+	"vat-offline":  validateVATRow,
+	"iban":         checkIBANRow,
+	"iban-offline": validateIBANRow,
+	"bban-offline": convertBBANRow,
+}
+
+// bulkRow is a row of the input file together with its result
+type bulkRow struct {
+	index  int
+	record []string
+	result bulkResult
+}
+
+// runBulkCheck checks a column of a CSV file and writes the file with the result columns appended
+//
+// When the output file already exists, the rows it contains are skipped, so an interrupted run can be resumed.
+func runBulkCheck(env *environment, args []string) int {
+
+	fs := env.baseFlagSet("bulk check", "<file.csv>")
+	column := fs.String("column", "", "the name of the column which contains the values to check")
+	check := fs.String("check", "vat", "the check to run: vat, vat-offline, iban, iban-offline or bban-offline")
+	output := fs.String("output", "", "the file to write the results to, processing resumes when it already exists (default stdout)")
+	workers := fs.Int("workers", 4, "the number of rows to check concurrently")
+	delimiter := fs.String("delimiter", ",", "the field delimiter of the CSV file")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	checker, ok := bulkCheckers[*check]
+	if !ok {
+		return env.fail(exitUsage, errors.New("invalid check: "+*check))
+	}
+
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if size == 0 || size != len(*delimiter) {
+		return env.fail(exitUsage, errors.New("invalid delimiter: "+*delimiter))
+	}
+
+	if fs.NArg() != 1 || *column == "" || *workers < 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	header, rows, err := readBulkInput(fs.Arg(0), comma)
+	if err != nil {
+		return env.fail(exitUsage, err)
+	}
+
+	columnIndex := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), *column) {
+			columnIndex = i
+			break
+		}
+	}
+	if columnIndex < 0 {
+		return env.fail(exitUsage, errors.New("column not found: "+*column))
+	}
+
+	outputHeader := append(append([]string{}, header...), bulkResultColumns...)
+
+	var out io.Writer = env.stdout
+	done := 0
+
+	if *output != "" {
+		f, processed, err := openBulkOutput(*output, outputHeader, rows, comma)
+		if err != nil {
+			return env.fail(exitUsage, err)
+		}
+		defer f.Close()
+		out = f
+		done = processed
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Comma = comma
+
+	if *output == "" {
+		writer.Write(outputHeader)
+		writer.Flush()
+	}
+
+	client := env.client()
+	written, code, err := processBulkRows(env.ctx, rows[done:], *workers, func(ctx context.Context, record []string) bulkResult {
+		value := ""
+		if columnIndex < len(record) {
+			value = strings.TrimSpace(record[columnIndex])
+		}
+		if value == "" {
+			return bulkResult{err: errors.New("empty value"), code: exitInvalid}
+		}
+		return checker(ctx, client, value)
+	}, func(row bulkRow) error {
+		if err := writer.Write(append(padRecord(row.record, len(header)), row.result.fields()...)); err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+
+	fmt.Fprintf(env.stderr, "%d rows checked, %d rows skipped, %d rows remaining\n", written, done, len(rows)-done-written)
+
+	if err != nil {
+		return env.fail(exitServiceError, err)
+	}
+	if env.ctx.Err() != nil {
+		return env.fail(exitServiceError, env.ctx.Err())
+	}
+
+	return code
+
+}
+
+// processBulkRows checks the rows concurrently and writes the results in the order of the rows
+//
+// It stops writing as soon as the context is cancelled, so that no row is written with an incomplete result. A row
+// which couldn't be checked because a service failed stops the writing as well, so that resuming checks it again.
+// When writing stops, the remaining rows are cancelled so they aren't sent to the services anymore.
+// It returns the number of rows which were written, the most severe exit code and the error which stopped the writing.
+func processBulkRows(ctx context.Context, rows [][]string, workers int, check func(context.Context, []string) bulkResult, write func(bulkRow) error) (int, int, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan bulkRow)
+	results := make(chan bulkRow)
+
+	go func() {
+		defer close(jobs)
+		for i, record := range rows {
+			select {
+			case jobs <- bulkRow{index: i, record: record}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				row.result = check(ctx, row.record)
+				results <- row
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	code := exitOK
+	next := 0
+	pending := make(map[int]bulkRow)
+
+	var stopErr error
+
+	for row := range results {
+
+		pending[row.index] = row
+
+		for stopErr == nil && ctx.Err() == nil {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			if ready.result.code == exitServiceError {
+				code = exitServiceError
+				stopErr = ready.result.err
+				cancel()
+				break
+			}
+			if stopErr = write(ready); stopErr != nil {
+				cancel()
+				break
+			}
+			delete(pending, next)
+			code = worse(code, ready.result.code)
+			next++
+		}
+
+	}
+
+	return next, code, stopErr
+
+}
+
+// readBulkInput reads the header and the rows of the input file
+func readBulkInput(path string, comma rune) ([]string, [][]string, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil, errors.New("the input file is empty: " + path)
+	}
+
+	return records[0], records[1:], nil
+
+}
+
+// openBulkOutput opens the output file for appending and returns the number of rows it already contains
+//
+// The rows of an existing file are compared with the input. A row which was only partially written when the previous
+// run was interrupted is removed.
+func openBulkOutput(path string, header []string, rows [][]string, comma rune) (*os.File, int, error) {
+
+	existing, err := readBulkOutput(path, header, rows, comma)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".bulk-*.csv")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := csv.NewWriter(tmp)
+	writer.Comma = comma
+	writer.Write(header)
+	writer.WriteAll(existing)
+	if err := writer.Error(); err != nil {
+		tmp.Close()
+		return nil, 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return f, len(existing), nil
+
+}
+
+// readBulkOutput returns the complete rows of an existing output file
+func readBulkOutput(path string, header []string, rows [][]string, comma rune) ([][]string, error) {
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+
+	existingHeader, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil || !equalRecords(existingHeader, header) {
+		return nil, errors.New("the output file doesn't belong to this input, remove it to start over: " + path)
+	}
+
+	var result [][]string
+
+	for len(result) < len(rows) {
+
+		record, err := reader.Read()
+		input := padRecord(rows[len(result)], len(header)-len(bulkResultColumns))
+		if err != nil || len(record) != len(input)+len(bulkResultColumns) {
+			break
+		}
+
+		if !equalRecords(record[:len(input)], input) {
+			return nil, errors.New("row " + strconv.Itoa(len(result)+2) + " of the output file doesn't match the input: " + path)
+		}
+
+		result = append(result, record)
+
+	}
+
+	return result, nil
+
+}
+
+// fields returns the result columns
+func (r bulkResult) fields() []string {
+	return []string{strconv.FormatBool(r.valid), r.name, r.address, r.iban, r.bic, errorString(r.err)}
+}
+
+// checkVATRow checks a VAT number with VIES
+func checkVATRow(ctx context.Context, client *finance.Client, value string) bulkResult {
+	info, err := client.CheckVATContext(ctx, value)
+	if err != nil {
+		return bulkResult{err: err, code: vatExitCode(err)}
+	}
+	result := bulkResult{valid: info.IsValid, name: info.Name, address: info.Address}
+	if !info.IsValid {
+		result.code = exitInvalid
+	}
+	return result
+}
+
+// validateVATRow validates a VAT number offline
+func validateVATRow(_ context.Context, _ *finance.Client, value string) bulkResult {
+	if err := finance.ValidateVAT(value); err != nil {
+		return bulkResult{err: err, code: exitInvalid}
+	}
+	return bulkResult{valid: true}
+}
+
+// checkIBANRow looks up the IBAN and BIC of a Belgian bank account number with the IBANBIC service
+func checkIBANRow(ctx context.Context, client *finance.Client, value string) bulkResult {
+	info, err := client.CheckIBANContext(ctx, value)
+	if err != nil {
+		return bulkResult{err: err, code: ibanExitCode(err)}
+	}
+	return bulkResult{valid: true, name: info.BankName, iban: info.IBAN, bic: info.BIC}
+}
+
+// validateIBANRow validates an IBAN offline
+func validateIBANRow(_ context.Context, _ *finance.Client, value string) bulkResult {
+	if err := finance.ValidateIBAN(value); err != nil {
+		return bulkResult{err: err, code: exitInvalid}
+	}
+	return bulkResult{valid: true, iban: finance.FormatIBAN(value)}
+}
+
+// convertBBANRow computes the IBAN and BIC of a Belgian bank account number offline
+func convertBBANRow(_ context.Context, _ *finance.Client, value string) bulkResult {
+	info, err := finance.ConvertBBAN(value)
+	if err != nil {
+		return bulkResult{err: err, code: exitInvalid}
+	}
+	return bulkResult{valid: true, name: info.BankName, iban: info.IBAN, bic: info.BIC}
+}
+
+// padRecord returns the record extended with empty fields up to the given length, longer records are kept whole
+func padRecord(record []string, length int) []string {
+	result := append([]string{}, record...)
+	for len(result) < length {
+		result = append(result, "")
+	}
+	return result
+}
+
+// equalRecords returns true when both records contain the same fields
+func equalRecords(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
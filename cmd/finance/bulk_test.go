@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeBulkFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readBulkFile(t *testing.T, path string) string {
+	rawData, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(rawData)
+}
+
+func TestBulkCheck(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := writeBulkFile(t, dir, "input.csv", "customer;account\nacme;BE68539007547034\nglobex;BE69539007547034\ninitech;\n")
+	output := filepath.Join(dir, "output.csv")
+
+	code, _, _ := runTest(t, "", "bulk", "check", "--check", "iban-offline", "--column", "Account", "--delimiter", ";", "--output", output, input)
+
+	assert.Equal(t, exitInvalid, code, "exit-code")
+	assert.Equal(t, "customer;account;valid;name;address;iban;bic;error\n"+
+		"acme;BE68539007547034;true;;;BE68 5390 0754 7034;;\n"+
+		"globex;BE69539007547034;false;;;;;IBAN checksum is invalid: BE69539007547034\n"+
+		"initech;;false;;;;;empty value\n", readBulkFile(t, output), "output")
+
+}
+
+func TestBulkCheckVAT(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := writeBulkFile(t, dir, "input.csv", "vat\nBE0836157420\nBE0000000097\nBE0836157420\nBE0000000097\nBE0836157420\n")
+
+	code, stdout, _ := runTest(t, "", "bulk", "check", "--column", "vat", "--workers", "3", input)
+
+	assert.Equal(t, exitInvalid, code, "exit-code")
+	assert.Equal(t, "vat,valid,name,address,iban,bic,error\n"+
+		"BE0836157420,true,SRL APPLE RETAIL BELGIUM,\"Avenue du Port 86C/204\n1000 Bruxelles\",,,\n"+
+		"BE0000000097,false,,,,,\n"+
+		"BE0836157420,true,SRL APPLE RETAIL BELGIUM,\"Avenue du Port 86C/204\n1000 Bruxelles\",,,\n"+
+		"BE0000000097,false,,,,,\n"+
+		"BE0836157420,true,SRL APPLE RETAIL BELGIUM,\"Avenue du Port 86C/204\n1000 Bruxelles\",,,\n", stdout, "output")
+
+}
+
+func TestBulkCheckResume(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := writeBulkFile(t, dir, "input.csv", "account\n738-1202561-74\n001-2345678-03\n")
+	output := writeBulkFile(t, dir, "output.csv", "account,valid,name,address,iban,bic,error\n"+
+		"738-1202561-74,true,FROM PREVIOUS RUN,,BE16 7381 2025 6174,KRED BE BB,\n"+
+		"001-2345678-03,tr")
+
+	code, _, stderr := runTest(t, "", "bulk", "check", "--check", "bban-offline", "--column", "account", "--output", output, input)
+
+	assert.Equal(t, exitOK, code, "exit-code")
+	assert.Contains(t, stderr, "1 rows checked, 1 rows skipped, 0 rows remaining", "summary")
+
+	actual := readBulkFile(t, output)
+	assert.Contains(t, actual, "738-1202561-74,true,FROM PREVIOUS RUN,", "kept")
+	assert.Contains(t, actual, "\n001-2345678-03,true,", "resumed")
+	assert.NotContains(t, actual, ",tr\n", "partial-row")
+
+	mismatch := writeBulkFile(t, dir, "mismatch.csv", "account,valid,name,address,iban,bic,error\nOTHER,true,,,,,\n")
+	code, _, _ = runTest(t, "", "bulk", "check", "--check", "bban-offline", "--column", "account", "--output", mismatch, input)
+	assert.Equal(t, exitUsage, code, "mismatch")
+
+}
+
+func TestBulkCheckExtraFields(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := writeBulkFile(t, dir, "input.csv", "account\n738-1202561-74,extra,fields\n001-2345678-03\n")
+	output := filepath.Join(dir, "output.csv")
+
+	code, _, _ := runTest(t, "", "bulk", "check", "--check", "bban-offline", "--column", "account", "--output", output, input)
+
+	assert.Equal(t, exitOK, code, "exit-code")
+	assert.Contains(t, readBulkFile(t, output), "\n738-1202561-74,extra,fields,true,", "kept")
+
+	code, _, stderr := runTest(t, "", "bulk", "check", "--check", "bban-offline", "--column", "account", "--output", output, input)
+
+	assert.Equal(t, exitOK, code, "resume-exit-code")
+	assert.Contains(t, stderr, "0 rows checked, 2 rows skipped, 0 rows remaining", "resume-summary")
+
+}
+
+func TestProcessBulkRowsWriteError(t *testing.T) {
+
+	rows := make([][]string, 100)
+	for i := range rows {
+		rows[i] = []string{"BE0836157420"}
+	}
+
+	var checked int32
+	check := func(ctx context.Context, record []string) bulkResult {
+		if ctx.Err() == nil {
+			atomic.AddInt32(&checked, 1)
+		}
+		return bulkResult{valid: true}
+	}
+
+	writeErr := errors.New("disk full")
+	written, _, err := processBulkRows(context.Background(), rows, 1, check, func(row bulkRow) error {
+		return writeErr
+	})
+
+	assert.Equal(t, writeErr, err, "error")
+	assert.Equal(t, 0, written, "written")
+	assert.True(t, atomic.LoadInt32(&checked) < 10, "checked")
+
+}
+
+func TestProcessBulkRowsServiceError(t *testing.T) {
+
+	rows := [][]string{{"1"}, {"2"}, {"3"}, {"4"}, {"5"}}
+
+	serviceErr := errors.New("service unavailable")
+	check := func(ctx context.Context, record []string) bulkResult {
+		if record[0] == "3" {
+			return bulkResult{err: serviceErr, code: exitServiceError}
+		}
+		return bulkResult{valid: true}
+	}
+
+	var written []string
+	count, code, err := processBulkRows(context.Background(), rows, 2, check, func(row bulkRow) error {
+		written = append(written, row.record[0])
+		return nil
+	})
+
+	assert.Equal(t, serviceErr, err, "error")
+	assert.Equal(t, exitServiceError, code, "exit-code")
+	assert.Equal(t, 2, count, "count")
+	assert.Equal(t, []string{"1", "2"}, written, "written")
+
+}
+
+func TestBulkCheckUsage(t *testing.T) {
+
+	type test struct {
+		name string
+		args []string
+	}
+
+	var tests = []test{
+		{"missing-file", []string{"--column", "vat"}},
+		{"missing-column", []string{"input.csv"}},
+		{"unknown-check", []string{"--check", "bic", "--column", "vat", "input.csv"}},
+		{"invalid-delimiter", []string{"--delimiter", ";;", "--column", "vat", "input.csv"}},
+		{"file-not-found", []string{"--column", "vat", "does-not-exist.csv"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, _ := runTest(t, "", append([]string{"bulk", "check"}, tc.args...)...)
+			assert.Equal(t, exitUsage, code, "exit-code")
+		})
+	}
+
+}
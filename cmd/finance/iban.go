@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// ibanRecord is the result of checking or validating a single bank account number
+type ibanRecord struct {
+	Input    string `json:"input"`
+	Valid    bool   `json:"valid"`
+	IBAN     string `json:"iban,omitempty"`
+	BIC      string `json:"bic,omitempty"`
+	BankName string `json:"bank_name,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// runIBANCheck looks up the IBAN and BIC of Belgian bank account numbers with the IBANBIC service
+func runIBANCheck(env *environment, args []string) int {
+
+	fs := env.flagSet("iban check", "[account-number...]")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	inputs, err := env.inputs(fs.Args())
+	if err != nil {
+		return env.fail(exitUsage, err)
+	}
+
+	client := env.client()
+
+	code := exitOK
+	records := make([]ibanRecord, 0, len(inputs))
+
+	for _, input := range inputs {
+
+		record := ibanRecord{Input: input}
+
+		info, err := client.CheckIBANContext(env.ctx, input)
+		if err != nil {
+			record.Error = err.Error()
+			code = worse(code, ibanExitCode(err))
+		} else {
+			record.Valid = true
+			record.IBAN = info.IBAN
+			record.BIC = info.BIC
+			record.BankName = info.BankName
+		}
+
+		records = append(records, record)
+
+	}
+
+	if err := env.write(ibanReport(records)); err != nil {
+		return env.fail(exitServiceError, err)
+	}
+
+	return code
+
+}
+
+// runIBANValidate validates IBANs offline
+func runIBANValidate(env *environment, args []string) int {
+
+	fs := env.flagSet("iban validate", "[iban...]")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	inputs, err := env.inputs(fs.Args())
+	if err != nil {
+		return env.fail(exitUsage, err)
+	}
+
+	code := exitOK
+	records := make([]ibanRecord, 0, len(inputs))
+
+	for _, input := range inputs {
+
+		record := ibanRecord{Input: input}
+
+		if err := finance.ValidateIBAN(input); err != nil {
+			record.Error = err.Error()
+			code = worse(code, exitInvalid)
+		} else {
+			record.Valid = true
+			record.IBAN = finance.FormatIBAN(input)
+		}
+
+		records = append(records, record)
+
+	}
+
+	if err := env.write(ibanReport(records)); err != nil {
+		return env.fail(exitServiceError, err)
+	}
+
+	return code
+
+}
+
+// ibanExitCode returns the exit code for an error returned when checking a bank account number
+func ibanExitCode(err error) int {
+	if errors.Is(err, finance.ErrIBANBICInvalidInput) {
+		return exitInvalid
+	}
+	return exitServiceError
+}
+
+// ibanReport returns the report for a list of bank account numbers
+func ibanReport(records []ibanRecord) *report {
+
+	r := &report{
+		columns: []string{"input", "valid", "iban", "bic", "bank", "error"},
+		records: records,
+	}
+
+	for _, record := range records {
+		r.rows = append(r.rows, []string{
+			record.Input, strconv.FormatBool(record.Valid), record.IBAN, record.BIC, record.BankName, record.Error,
+		})
+	}
+
+	return r
+
+}
@@ -0,0 +1,252 @@
+// Command finance checks VAT numbers and bank accounts and converts currencies from the command line.
+//
+// Usage:
+//
+//	finance <group> <command> [flags] [arguments]
+//
+// The commands read their arguments from stdin, one per line, when none are given. Run "finance help" for the list
+// of commands.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// The exit codes of the command
+const (
+	exitOK           = 0 // Everything was checked and is valid
+	exitInvalid      = 1 // At least one of the inputs is invalid
+	exitUsage        = 2 // The command was used incorrectly
+	exitServiceError = 3 // A remote service couldn't be used, the result is unknown
+)
+
+// command defines a subcommand
+type command struct {
+	group   string
+	name    string
+	args    string
+	summary string
+	run     func(env *environment, args []string) int
+}
+
+// commands lists all available subcommands
+var commands = []command{
+	{"vat", "check", "[vat-number...]", "Check VAT numbers with VIES", runVATCheck},
+	{"iban", "check", "[account-number...]", "Look up the IBAN and BIC of Belgian bank account numbers", runIBANCheck},
+	{"iban", "validate", "[iban...]", "Validate IBANs offline", runIBANValidate},
+	{"rates", "list", "[currency...]", "List the ECB reference rates", runRatesList},
+	{"rates", "convert", "[amount from to]", "Convert an amount from one currency to another", runRatesConvert},
+	{"bulk", "check", "<file.csv>", "Check a column of a CSV file and append the results", runBulkCheck},
+	{"serve", "", "", "Serve the checks and the exchange rates as a JSON API", runServe},
+}
+
+// clientOptions are applied to each client the commands create
+var clientOptions []finance.ClientOption
+
+// environment contains the streams and the settings shared by all commands
+type environment struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	format  string
+	timeout time.Duration
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments and returns the exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	env := &environment{
+		ctx:    ctx,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		format: formatText,
+	}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.group == args[0] && cmd.name == "" {
+			return cmd.run(env, args[1:])
+		}
+		if cmd.group == args[0] && len(args) > 1 && cmd.name == args[1] {
+			return cmd.run(env, args[2:])
+		}
+	}
+
+	fmt.Fprintln(stderr, "ERROR: unknown command:", strings.Join(args[:minInt(len(args), 2)], " "))
+	printUsage(stderr)
+	return exitUsage
+
+}
+
+// printUsage prints the list of commands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: finance <group> <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-15s %-20s %s\n", strings.TrimSpace(cmd.group+" "+cmd.name), cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Arguments are read from stdin, one per line, when none are given.")
+	fmt.Fprintln(w, "Exit codes: 0 valid, 1 invalid input, 2 usage error, 3 service error")
+}
+
+// flagSet returns a flag set for a command with the flags shared by all commands
+func (env *environment) flagSet(name string, args string) *flag.FlagSet {
+	fs := env.baseFlagSet(name, args)
+	fs.StringVar(&env.format, "format", formatText, "the output format: text, json or csv")
+	return fs
+}
+
+// baseFlagSet returns a flag set for a command with the flags shared by all commands, except for the output format
+func (env *environment) baseFlagSet(name string, args string) *flag.FlagSet {
+
+	fs := flag.NewFlagSet("finance "+name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.DurationVar(&env.timeout, "timeout", 0, "the timeout for each request to a remote service")
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "Usage: finance %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+
+}
+
+// parse parses the flags of a command and validates the shared flags
+//
+// The flag package stops at the first argument, so the flags which follow the arguments are parsed in a next round,
+// e.g. finance vat check BE0836157420 --format json. Everything after -- is an argument, even when it starts with -.
+func (env *environment) parse(fs *flag.FlagSet, args []string) bool {
+
+	var positional []string
+
+	for len(args) > 0 {
+
+		if err := fs.Parse(args); err != nil {
+			return false
+		}
+
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+
+		if len(rest) > 0 {
+			positional = append(positional, rest[0])
+			rest = rest[1:]
+		}
+		args = rest
+
+	}
+
+	if err := fs.Parse(append([]string{"--"}, positional...)); err != nil {
+		return false
+	}
+
+	switch env.format {
+	case formatText, formatJSON, formatCSV:
+		return true
+	}
+
+	fmt.Fprintln(env.stderr, "ERROR: invalid format:", env.format)
+	return false
+
+}
+
+// client returns a client configured with the shared flags and the given options
+func (env *environment) client(opts ...finance.ClientOption) *finance.Client {
+
+	all := append([]finance.ClientOption{}, clientOptions...)
+	if env.timeout > 0 {
+		all = append(all, finance.WithTimeout(env.timeout))
+	}
+
+	return finance.NewClient(append(all, opts...)...)
+
+}
+
+// inputs returns the positional arguments, or the non-empty lines from stdin when there are none
+func (env *environment) inputs(args []string) ([]string, error) {
+
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	var result []string
+
+	scanner := bufio.NewScanner(env.stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return result, scanner.Err()
+
+}
+
+// fail prints an error and returns the given exit code
+func (env *environment) fail(code int, err error) int {
+	fmt.Fprintln(env.stderr, "ERROR:", err.Error())
+	return code
+}
+
+// minInt returns the smallest of two integers
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// worse returns the most severe of two exit codes
+func worse(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
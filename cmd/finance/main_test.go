@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
+)
+
+const testRatesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2024-01-03">
+			<Cube currency="USD" rate="1.0919"/>
+			<Cube currency="JPY" rate="155.52"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const testVATResponse = `<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body><ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types"><ns2:countryCode>BE</ns2:countryCode><ns2:vatNumber>%s</ns2:vatNumber><ns2:valid>%s</ns2:valid><ns2:name>SRL APPLE RETAIL BELGIUM</ns2:name><ns2:address>Avenue du Port 86C/204
+1000 Bruxelles</ns2:address></ns2:checkVatResponse></env:Body></env:Envelope>`
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			if r.URL.Path == "/rates" {
+				w.Write([]byte(testRatesXML))
+				return
+			}
+
+			body, _ := ioutil.ReadAll(r.Body)
+			switch {
+			case bytes.Contains(body, []byte("0836157420")):
+				w.Write([]byte(strings.Replace(strings.Replace(testVATResponse, "%s", "0836157420", 1), "%s", "true", 1)))
+			case bytes.Contains(body, []byte("0000000097")):
+				w.Write([]byte(strings.Replace(strings.Replace(testVATResponse, "%s", "0000000097", 1), "%s", "false", 1)))
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>MS_UNAVAILABLE</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+			}
+
+		}),
+	)
+}
+
+func runTest(t *testing.T, stdin string, args ...string) (int, string, string) {
+
+	s := newTestServer()
+	defer s.Close()
+
+	ibanbic := financetest.NewIBANBICServer()
+	defer ibanbic.Close()
+
+	clientOptions = append([]finance.ClientOption{
+		finance.WithVATServiceURL(s.URL + "/vies"),
+		finance.WithRatesURL(s.URL + "/rates"),
+	}, ibanbic.ClientOptions()...)
+	defer func() {
+		clientOptions = nil
+	}()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+
+}
+
+func TestUsage(t *testing.T) {
+
+	type test struct {
+		name     string
+		args     []string
+		expected int
+	}
+
+	var tests = []test{
+		{"no-args", []string{}, exitUsage},
+		{"help", []string{"help"}, exitOK},
+		{"missing-command", []string{"vat"}, exitUsage},
+		{"unknown-command", []string{"vat", "guess"}, exitUsage},
+		{"invalid-format", []string{"iban", "validate", "--format", "xml", "BE68539007547034"}, exitUsage},
+		{"invalid-flag", []string{"iban", "validate", "--nope"}, exitUsage},
+		{"convert-missing-args", []string{"rates", "convert", "10", "EUR"}, exitUsage},
+		{"invalid-trailing-flag", []string{"iban", "validate", "BE68539007547034", "--nope"}, exitUsage},
+		{"invalid-trailing-format", []string{"iban", "validate", "BE68539007547034", "--format", "xml"}, exitUsage},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, _ := runTest(t, "", tc.args...)
+			assert.Equal(t, tc.expected, code, "exit-code")
+		})
+	}
+
+}
+
+func TestVATCheck(t *testing.T) {
+
+	type test struct {
+		name     string
+		args     []string
+		stdin    string
+		expected int
+	}
+
+	var tests = []test{
+		{"valid", []string{"BE0836157420"}, "", exitOK},
+		{"invalid", []string{"BE0836157420", "BE0000000097"}, "", exitInvalid},
+		{"too-short", []string{"BE"}, "", exitInvalid},
+		{"prevalidated", []string{"--prevalidate", "BE0836157421"}, "", exitInvalid},
+		{"service-error", []string{"BE0836157420", "BE0000000097", "DE123456789"}, "", exitServiceError},
+		{"stdin", []string{}, "BE0836157420\n\n# comment\nBE 0836.157.420\n", exitOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, _ := runTest(t, tc.stdin, append([]string{"vat", "check"}, tc.args...)...)
+			assert.Equal(t, tc.expected, code, "exit-code")
+		})
+	}
+
+	code, stdout, _ := runTest(t, "", "vat", "check", "BE0836157420", "--format", "json")
+	assert.Equal(t, exitOK, code, "json-exit-code")
+
+	var records []vatRecord
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &records), "json")
+	if assert.Len(t, records, 1, "records") {
+		assert.True(t, records[0].Valid, "valid")
+		assert.Equal(t, "SRL APPLE RETAIL BELGIUM", records[0].Name, "name")
+	}
+
+}
+
+func TestIBANCheck(t *testing.T) {
+
+	type test struct {
+		name     string
+		args     []string
+		expected int
+	}
+
+	var tests = []test{
+		{"valid", []string{"738-1202561-74"}, exitOK},
+		{"rejected-by-service", []string{"738-1202561-75"}, exitInvalid},
+		{"mixed", []string{"738-1202561-74", "738-1202561-75"}, exitInvalid},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, _, _ := runTest(t, "", append([]string{"iban", "check"}, tc.args...)...)
+			assert.Equal(t, tc.expected, code, "exit-code")
+		})
+	}
+
+}
+
+func TestIBANValidate(t *testing.T) {
+
+	code, stdout, _ := runTest(t, "", "iban", "validate", "--format", "csv", "be68539007547034", "BE69539007547034")
+
+	assert.Equal(t, exitInvalid, code, "exit-code")
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if assert.Len(t, lines, 3, "lines") {
+		assert.Equal(t, "input,valid,iban,bic,bank,error", lines[0], "header")
+		assert.Equal(t, "be68539007547034,true,BE68 5390 0754 7034,,,", lines[1], "valid")
+		assert.True(t, strings.HasPrefix(lines[2], "BE69539007547034,false,,,,"), "invalid")
+	}
+
+}
+
+func TestParseFlagsAfterArguments(t *testing.T) {
+
+	type test struct {
+		name           string
+		args           []string
+		expectedArgs   []string
+		expectedFormat string
+		expectedOK     bool
+	}
+
+	var tests = []test{
+		{"flags-first", []string{"--format", "json", "a", "b"}, []string{"a", "b"}, formatJSON, true},
+		{"flags-last", []string{"a", "b", "--format", "json"}, []string{"a", "b"}, formatJSON, true},
+		{"flags-between", []string{"a", "--format=csv", "b"}, []string{"a", "b"}, formatCSV, true},
+		{"terminator", []string{"a", "--", "--format", "json"}, []string{"a", "--format", "json"}, formatText, true},
+		{"no-args", []string{}, []string{}, formatText, true},
+		{"unknown-flag", []string{"a", "--nope"}, nil, formatText, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			var stderr bytes.Buffer
+			env := &environment{stderr: &stderr}
+			fs := env.flagSet("test", "")
+
+			assert.Equal(t, tc.expectedOK, env.parse(fs, tc.args), "parse")
+			if !tc.expectedOK {
+				return
+			}
+			assert.Equal(t, tc.expectedArgs, fs.Args(), "args")
+			assert.Equal(t, tc.expectedFormat, env.format, "format")
+
+		})
+	}
+
+}
+
+func TestRates(t *testing.T) {
+
+	code, stdout, _ := runTest(t, "", "rates", "list", "--format", "csv", "usd")
+	assert.Equal(t, exitOK, code, "list-exit-code")
+	assert.Equal(t, "currency,rate,date\nUSD,1.0919,2024-01-03\n", stdout, "list")
+
+	code, _, _ = runTest(t, "", "rates", "list", "CHF")
+	assert.Equal(t, exitInvalid, code, "list-not-quoted")
+
+	code, stdout, _ = runTest(t, "", "rates", "convert", "--format", "json", "100", "eur", "jpy")
+	assert.Equal(t, exitOK, code, "convert-exit-code")
+
+	var records []conversionRecord
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &records), "json")
+	if assert.Len(t, records, 1, "records") {
+		assert.Equal(t, "15552", records[0].Result, "result")
+		assert.Equal(t, "JPY", records[0].To, "to")
+		assert.Equal(t, "2024-01-03", records[0].Date, "date")
+	}
+
+	code, stdout, _ = runTest(t, "10 USD EUR\n1 EUR ABC\n", "rates", "convert", "--format", "csv", "--rounding", "down")
+	assert.Equal(t, exitInvalid, code, "stdin-exit-code")
+	assert.Contains(t, stdout, "10,USD,EUR,0.915834783405,9.15,2024-01-03,", "stdin-result")
+
+	code, _, _ = runTest(t, "", "rates", "convert", "--rounding", "up", "1", "EUR", "USD")
+	assert.Equal(t, exitUsage, code, "invalid-rounding")
+
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// The supported output formats
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// report contains the output of a command
+type report struct {
+	columns []string    // The column names for the text and CSV output
+	rows    [][]string  // The rows for the text and CSV output
+	records interface{} // The value which is written as JSON
+}
+
+// write writes the report in the selected format
+func (env *environment) write(r *report) error {
+
+	switch env.format {
+
+	case formatJSON:
+		encoder := json.NewEncoder(env.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r.records)
+
+	case formatCSV:
+		writer := csv.NewWriter(env.stdout)
+		if err := writer.Write(r.columns); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(r.rows); err != nil {
+			return err
+		}
+		return writer.Error()
+
+	default:
+		writer := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(r.columns, "\t")))
+		for _, row := range r.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = strings.Replace(cell, "\n", ", ", -1)
+			}
+			fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		}
+		return writer.Flush()
+
+	}
+
+}
+
+// errorString returns the message of an error, or an empty string when there is none
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// recentRatesDays is the age up to which a date is looked up in the rates of the last 90 days
+const recentRatesDays = 85
+
+// rateRecord is a single exchange rate
+type rateRecord struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Date     string  `json:"date"`
+}
+
+// conversionRecord is the result of a single conversion
+type conversionRecord struct {
+	Input  string `json:"input"`
+	Amount string `json:"amount,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+	Rate   string `json:"rate,omitempty"`
+	Result string `json:"result,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// runRatesList lists the exchange rates
+func runRatesList(env *environment, args []string) int {
+
+	fs := env.flagSet("rates list", "[currency...]")
+	date := fs.String("date", "", "use the rates of this date (YYYY-MM-DD), or of the previous business day")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	table, code, err := loadRates(env, *date)
+	if err != nil {
+		return env.fail(code, err)
+	}
+
+	currencies := fs.Args()
+	if len(currencies) == 0 {
+		for currency := range table.Rates {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+	}
+
+	code = exitOK
+	records := make([]rateRecord, 0, len(currencies))
+	r := &report{columns: []string{"currency", "rate", "date"}}
+
+	for _, currency := range currencies {
+
+		normalized, err := finance.NormalizeCurrency(currency)
+		if err == nil {
+			if _, ok := table.Rates[normalized]; !ok {
+				err = fmt.Errorf("%s: %v", normalized, finance.ErrCurrencyNotQuoted)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(env.stderr, "ERROR:", err.Error())
+			code = exitInvalid
+			continue
+		}
+
+		record := rateRecord{
+			Currency: normalized,
+			Rate:     table.Rates[normalized],
+			Date:     table.Date.Format("2006-01-02"),
+		}
+
+		records = append(records, record)
+		r.rows = append(r.rows, []string{record.Currency, strconv.FormatFloat(record.Rate, 'f', -1, 64), record.Date})
+
+	}
+
+	r.records = records
+	if err := env.write(r); err != nil {
+		return env.fail(exitServiceError, err)
+	}
+
+	return code
+
+}
+
+// runRatesConvert converts amounts from one currency to another
+func runRatesConvert(env *environment, args []string) int {
+
+	fs := env.flagSet("rates convert", "[amount from to]")
+	date := fs.String("date", "", "use the rates of this date (YYYY-MM-DD), or of the previous business day")
+	precision := fs.Int("precision", -1, "the number of decimals of the result, the minor unit of the target currency when negative")
+	rounding := fs.String("rounding", finance.RoundHalfEven.String(), "the rounding mode: half-even, half-up or down")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	mode, err := parseRoundingMode(*rounding)
+	if err != nil {
+		return env.fail(exitUsage, err)
+	}
+
+	var inputs []string
+	switch len(fs.Args()) {
+	case 0:
+		if inputs, err = env.inputs(nil); err != nil {
+			return env.fail(exitUsage, err)
+		}
+	case 3:
+		inputs = []string{strings.Join(fs.Args(), " ")}
+	default:
+		fs.Usage()
+		return exitUsage
+	}
+
+	table, code, err := loadRates(env, *date)
+	if err != nil {
+		return env.fail(code, err)
+	}
+
+	code = exitOK
+	records := make([]conversionRecord, 0, len(inputs))
+	r := &report{columns: []string{"amount", "from", "to", "rate", "result", "date", "error"}}
+
+	for _, input := range inputs {
+
+		record := conversionRecord{Input: input}
+
+		if err := convert(table, input, *precision, mode, &record); err != nil {
+			record.Error = err.Error()
+			code = exitInvalid
+		}
+
+		records = append(records, record)
+		r.rows = append(r.rows, []string{record.Amount, record.From, record.To, record.Rate, record.Result, record.Date, record.Error})
+
+	}
+
+	r.records = records
+	if err := env.write(r); err != nil {
+		return env.fail(exitServiceError, err)
+	}
+
+	return code
+
+}
+
+// convert converts an input line in the form "amount from to" and stores the result in the record
+func convert(table *finance.ExchangeRateTable, input string, precision int, mode finance.RoundingMode, record *conversionRecord) error {
+
+	fields := strings.Fields(input)
+	if len(fields) != 3 {
+		return errors.New("expected an amount, a from and a to currency")
+	}
+
+	record.Amount = fields[0]
+
+	if precision < 0 {
+		currency, err := finance.LookupCurrency(fields[2])
+		if err != nil {
+			return err
+		}
+		precision = currency.MinorUnits
+		if precision < 0 {
+			precision = 4
+		}
+	}
+
+	conversion, err := table.ConvertDecimal(fields[0], fields[1], fields[2], finance.DecimalOptions{
+		Precision: precision,
+		Rounding:  mode,
+	})
+	if err != nil {
+		return err
+	}
+
+	record.From = conversion.From
+	record.To = conversion.To
+	record.Rate = conversion.Rate
+	record.Result = conversion.Result
+	record.Date = conversion.Date.Format("2006-01-02")
+
+	return nil
+
+}
+
+// loadRates returns the latest rates, or the rates of the given date
+//
+// When no rates were published on the date, the rates of the previous business day are used.
+func loadRates(env *environment, date string) (*finance.ExchangeRateTable, int, error) {
+
+	client := env.client()
+
+	if date == "" {
+		table, err := client.LatestExchangeRatesContext(env.ctx)
+		if err != nil {
+			return nil, exitServiceError, err
+		}
+		return table, exitOK, nil
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, exitUsage, errors.New("invalid date: " + date)
+	}
+
+	var rates *finance.HistoricalRates
+	if time.Since(day) < recentRatesDays*24*time.Hour {
+		rates, err = client.HistoricalExchangeRatesContext(env.ctx)
+	} else {
+		rates, err = client.FullHistoricalExchangeRatesContext(env.ctx)
+	}
+	if err != nil {
+		return nil, exitServiceError, err
+	}
+
+	rates.LookupPolicy = finance.LookupPreviousBusinessDay
+
+	table, err := rates.TableOn(day)
+	if err != nil {
+		return nil, exitInvalid, err
+	}
+
+	return table, exitOK, nil
+
+}
+
+// parseRoundingMode returns the rounding mode with the given name
+func parseRoundingMode(name string) (finance.RoundingMode, error) {
+	for _, mode := range []finance.RoundingMode{finance.RoundHalfEven, finance.RoundHalfUp, finance.RoundDown} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+	return 0, errors.New("invalid rounding mode: " + name)
+}
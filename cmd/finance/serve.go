@@ -0,0 +1,498 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// numberPattern matches the VAT and bank account numbers accepted by the API
+var numberPattern = regexp.MustCompile(`^[A-Za-z0-9 .\-]{3,40}$`)
+
+// readyProbeKey is the key written to the VAT cache to check that it can be used
+const readyProbeKey = "_READYZ"
+
+// server serves the library as a JSON API
+type server struct {
+	client   *finance.Client
+	rates    *finance.CachedRates
+	vatCache finance.VATCache // The cache of the VAT results, checked by /readyz
+	ibans    *ibanCache
+
+	readyTimeout time.Duration
+	draining     int32 // Set to 1 when the server is shutting down
+}
+
+// maxIBANCacheEntries is the number of account numbers the IBAN cache holds at most
+const maxIBANCacheEntries = 10000
+
+// ibanCache keeps the results of the IBANBIC service in memory
+//
+// The IBAN and BIC of a bank account number don't change, so each number only reaches the service once per TTL.
+type ibanCache struct {
+	ttl     time.Duration
+	size    int // The maximum number of entries
+	mu      sync.Mutex
+	entries map[string]ibanCacheEntry // The results, keyed by the account number without separators
+}
+
+// ibanCacheEntry is a result of the IBANBIC service together with the time it was looked up
+type ibanCacheEntry struct {
+	info      *finance.IBANBICInfo
+	checkedAt time.Time
+}
+
+// apiError is the body returned when a request fails
+type apiError struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// ratesResponse is the body returned by /rates
+type ratesResponse struct {
+	Date      string             `json:"date"`
+	Base      string             `json:"base"`
+	Source    string             `json:"source"`
+	Provider  string             `json:"provider"`
+	FetchedAt time.Time          `json:"fetched_at"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+// conversionResponse is the body returned by /rates/convert
+type conversionResponse struct {
+	Amount float64 `json:"amount"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Rate   float64 `json:"rate"`
+	Result float64 `json:"result"`
+	Date   string  `json:"date"`
+}
+
+// vatResponse is the body returned by /vat/{number}
+type vatResponse struct {
+	CountryCode string    `json:"country_code"`
+	VATNumber   string    `json:"vat_number"`
+	Valid       bool      `json:"valid"`
+	Name        string    `json:"name,omitempty"`
+	Address     string    `json:"address,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+	Stale       bool      `json:"stale"`
+}
+
+// ibanResponse is the body returned by /iban/{number}
+type ibanResponse struct {
+	BBAN     string `json:"bban"`
+	IBAN     string `json:"iban"`
+	BIC      string `json:"bic"`
+	BankName string `json:"bank_name"`
+}
+
+// runServe starts the HTTP API and stops it gracefully when the process is interrupted
+func runServe(env *environment, args []string) int {
+
+	fs := env.baseFlagSet("serve", "")
+	addr := fs.String("addr", ":8080", "the address to listen on")
+	validTTL := fs.Duration("vat-valid-ttl", 24*time.Hour, "how long a valid VAT number is cached")
+	invalidTTL := fs.Duration("vat-invalid-ttl", time.Hour, "how long an invalid VAT number is cached")
+	cacheDir := fs.String("vat-cache-dir", "", "the directory to persist the VAT cache in (default in memory)")
+	ibanTTL := fs.Duration("iban-ttl", 24*time.Hour, "how long the IBAN and BIC of a bank account number are cached")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 15*time.Second, "how long to wait for running requests when stopping")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	var cache finance.VATCache = finance.NewMemoryVATCache()
+	if *cacheDir != "" {
+		fileCache, err := finance.NewFileVATCache(*cacheDir)
+		if err != nil {
+			return env.fail(exitUsage, err)
+		}
+		cache = fileCache
+	}
+
+	s := newServer(env.client(
+		finance.WithRetryPolicy(finance.DefaultRetryPolicy),
+		finance.WithVATCache(finance.VATCachePolicy{
+			Cache:        cache,
+			ValidTTL:     *validTTL,
+			InvalidTTL:   *invalidTTL,
+			StaleIfError: true,
+			OnError: func(err error) {
+				fmt.Fprintln(env.stderr, "ERROR:", err.Error())
+			},
+		}),
+	), cache, *ibanTTL)
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	fmt.Fprintln(env.stderr, "Listening on", *addr)
+
+	select {
+	case err := <-errs:
+		return env.fail(exitServiceError, err)
+	case <-env.ctx.Done():
+	}
+
+	fmt.Fprintln(env.stderr, "Shutting down")
+	atomic.StoreInt32(&s.draining, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		return env.fail(exitServiceError, err)
+	}
+
+	return exitOK
+
+}
+
+// newServer returns a server which uses the given client, VAT cache and IBAN cache lifetime
+func newServer(client *finance.Client, vatCache finance.VATCache, ibanTTL time.Duration) *server {
+	return &server{
+		client:       client,
+		rates:        finance.NewCachedRates(client),
+		vatCache:     vatCache,
+		ibans:        newIBANCache(ibanTTL, maxIBANCacheEntries),
+		readyTimeout: 5 * time.Second,
+	}
+}
+
+// newIBANCache returns an empty IBAN cache which keeps at most size results for the given duration
+func newIBANCache(ttl time.Duration, size int) *ibanCache {
+	return &ibanCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]ibanCacheEntry),
+	}
+}
+
+// handler returns the routes of the API
+func (s *server) handler() http.Handler {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.HandleFunc("/rates", s.handleRates)
+	mux.HandleFunc("/rates/convert", s.handleConvert)
+	mux.HandleFunc("/vat/", s.handleVAT)
+	mux.HandleFunc("/iban/", s.handleIBAN)
+
+	return allowGET(mux)
+
+}
+
+// handleHealth reports that the process is alive
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady reports whether the server can serve exchange rates, use its VAT cache and isn't shutting down
+//
+// VIES itself isn't contacted, so probes don't add to its load. When VIES is down, cached VAT results are still served.
+func (s *server) handleReady(w http.ResponseWriter, r *http.Request) {
+
+	if atomic.LoadInt32(&s.draining) == 1 {
+		writeError(w, http.StatusServiceUnavailable, "shutting_down", errors.New("The server is shutting down"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.readyTimeout)
+	defer cancel()
+
+	if _, err := s.rates.LatestExchangeRatesContext(ctx); err != nil {
+		writeError(w, http.StatusServiceUnavailable, "rates_unavailable", err)
+		return
+	}
+
+	if err := s.checkVATCache(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, "vat_cache_unavailable", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+
+}
+
+// checkVATCache writes and reads a probe entry to check that the VAT cache can be used
+//
+// The probe has no result, so it's never served as the result of a VAT number.
+func (s *server) checkVATCache() error {
+
+	if s.vatCache == nil {
+		return errors.New("The server has no VAT cache")
+	}
+
+	if err := s.vatCache.Set(readyProbeKey, &finance.VATCacheEntry{CheckedAt: time.Now()}); err != nil {
+		return err
+	}
+
+	if _, found, err := s.vatCache.Get(readyProbeKey); err != nil || !found {
+		return errors.New("The VAT cache doesn't return its entries")
+	}
+
+	return nil
+
+}
+
+// handleRates returns the latest exchange rates
+func (s *server) handleRates(w http.ResponseWriter, r *http.Request) {
+
+	table, err := s.rates.LatestExchangeRatesContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "rates_unavailable", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ratesResponse{
+		Date:      table.Date.Format("2006-01-02"),
+		Base:      table.Base,
+		Source:    table.Source,
+		Provider:  table.Provider,
+		FetchedAt: table.FetchedAt,
+		Rates:     table.Rates,
+	})
+
+}
+
+// handleConvert converts an amount from one currency to another
+func (s *server) handleConvert(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+
+	amount, err := strconv.ParseFloat(query.Get("amount"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_amount", errors.New("The amount should be a number"))
+		return
+	}
+
+	from, err := finance.NormalizeCurrency(query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_currency", err)
+		return
+	}
+
+	to, err := finance.NormalizeCurrency(query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_currency", err)
+		return
+	}
+
+	table, err := s.rates.LatestExchangeRatesContext(r.Context())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "rates_unavailable", err)
+		return
+	}
+
+	conversion, err := table.Convert(amount, from, to)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "currency_not_quoted", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, conversionResponse{
+		Amount: conversion.Value,
+		From:   conversion.From,
+		To:     conversion.To,
+		Rate:   conversion.Rate,
+		Result: conversion.Result,
+		Date:   conversion.Date.Format("2006-01-02"),
+	})
+
+}
+
+// handleVAT checks a VAT number with VIES
+func (s *server) handleVAT(w http.ResponseWriter, r *http.Request) {
+
+	number, ok := pathNumber(w, r, "/vat/")
+	if !ok {
+		return
+	}
+
+	info, err := s.client.CheckVATContext(r.Context(), number)
+	if err != nil {
+		if vatExitCode(err) == exitInvalid {
+			writeError(w, http.StatusBadRequest, "invalid_vat_number", err)
+			return
+		}
+		writeError(w, serviceErrorStatus(err), "vies_unavailable", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, vatResponse{
+		CountryCode: info.CountryCode,
+		VATNumber:   info.VATNumber,
+		Valid:       info.IsValid,
+		Name:        info.Name,
+		Address:     info.Address,
+		CheckedAt:   info.CheckedAt,
+		Stale:       info.Stale,
+	})
+
+}
+
+// handleIBAN looks up the IBAN and BIC of a Belgian bank account number
+//
+// The check digits are validated first so that invalid numbers never reach the IBANBIC service. Each lookup costs
+// two requests to the service, so the results are cached.
+func (s *server) handleIBAN(w http.ResponseWriter, r *http.Request) {
+
+	number, ok := pathNumber(w, r, "/iban/")
+	if !ok {
+		return
+	}
+
+	if err := finance.ValidateBBAN(number); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_number", err)
+		return
+	}
+
+	info, err := s.ibans.lookup(r.Context(), s.client, number)
+	if err != nil {
+		if ibanExitCode(err) == exitInvalid {
+			writeError(w, http.StatusBadRequest, "invalid_account_number", err)
+			return
+		}
+		writeError(w, serviceErrorStatus(err), "ibanbic_unavailable", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ibanResponse{
+		BBAN:     info.BBAN,
+		IBAN:     info.IBAN,
+		BIC:      info.BIC,
+		BankName: info.BankName,
+	})
+
+}
+
+// lookup returns the cached result for a bank account number or looks it up with the IBANBIC service
+//
+// Only successful lookups are cached. Expired entries are removed when they are looked up, or when the cache is full.
+func (c *ibanCache) lookup(ctx context.Context, client *finance.Client, number string) (*finance.IBANBICInfo, error) {
+
+	key := strings.NewReplacer("-", "", ".", "", " ", "").Replace(number)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && time.Since(entry.checkedAt) >= c.ttl {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		return entry.info, nil
+	}
+
+	info, err := client.CheckIBANContext(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.size {
+		c.evict()
+	}
+	c.entries[key] = ibanCacheEntry{info: info, checkedAt: time.Now()}
+	c.mu.Unlock()
+
+	return info, nil
+
+}
+
+// evict removes the expired entries, or the oldest entry when none have expired
+//
+// The caller should hold the lock.
+func (c *ibanCache) evict() {
+
+	var oldestKey string
+	var oldest time.Time
+
+	for key, entry := range c.entries {
+		if time.Since(entry.checkedAt) >= c.ttl {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.checkedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.checkedAt
+		}
+	}
+
+	if len(c.entries) >= c.size {
+		delete(c.entries, oldestKey)
+	}
+
+}
+
+// pathNumber returns the number at the end of the path, or writes an error when it's invalid
+func pathNumber(w http.ResponseWriter, r *http.Request, prefix string) (string, bool) {
+
+	number := strings.TrimPrefix(r.URL.Path, prefix)
+	if !numberPattern.MatchString(number) {
+		writeError(w, http.StatusBadRequest, "invalid_number", errors.New("The number should contain 3 to 40 letters, digits, spaces, dots or dashes"))
+		return "", false
+	}
+
+	return number, true
+
+}
+
+// serviceErrorStatus returns the status code for an error of a remote service
+//
+// Temporary failures are reported as 503 so that clients know they can retry later.
+func serviceErrorStatus(err error) int {
+	var viesErr *finance.VIESError
+	if errors.As(err, &viesErr) && !viesErr.Retryable() {
+		return http.StatusBadGateway
+	}
+	return http.StatusServiceUnavailable
+}
+
+// allowGET rejects all requests which don't use the GET or HEAD method
+func allowGET(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", errors.New("Only GET requests are supported"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, code string, err error) {
+	writeJSON(w, status, apiError{Error: err.Error(), Code: code})
+}
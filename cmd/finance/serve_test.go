@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
+)
+
+// failingVATCache is a VAT cache which can't store anything
+type failingVATCache struct{}
+
+func (failingVATCache) Get(key string) (*finance.VATCacheEntry, bool, error) {
+	return nil, false, nil
+}
+
+func (failingVATCache) Set(key string, entry *finance.VATCacheEntry) error {
+	return errors.New("disk full")
+}
+
+func TestServe(t *testing.T) {
+
+	upstream := newTestServer()
+	defer upstream.Close()
+
+	cache := finance.NewMemoryVATCache()
+	s := newServer(finance.NewClient(
+		finance.WithVATServiceURL(upstream.URL+"/vies"),
+		finance.WithRatesURL(upstream.URL+"/rates"),
+		finance.WithIBANBICServiceURL(upstream.URL+"/ibanbic"),
+		finance.WithVATCache(finance.VATCachePolicy{Cache: cache}),
+	), cache, time.Hour)
+
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	type test struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}
+
+	var tests = []test{
+		{"health", "GET", "/healthz", http.StatusOK, ""},
+		{"ready", "GET", "/readyz", http.StatusOK, ""},
+		{"rates", "GET", "/rates", http.StatusOK, ""},
+		{"convert", "GET", "/rates/convert?amount=100&from=eur&to=jpy", http.StatusOK, ""},
+		{"convert-invalid-amount", "GET", "/rates/convert?amount=ten&from=EUR&to=USD", http.StatusBadRequest, "invalid_amount"},
+		{"convert-unknown-currency", "GET", "/rates/convert?amount=1&from=ABC&to=USD", http.StatusBadRequest, "invalid_currency"},
+		{"convert-not-quoted", "GET", "/rates/convert?amount=1&from=EUR&to=CHF", http.StatusUnprocessableEntity, "currency_not_quoted"},
+		{"vat-valid", "GET", "/vat/BE0836157420", http.StatusOK, ""},
+		{"vat-invalid", "GET", "/vat/BE0000000097", http.StatusOK, ""},
+		{"vat-too-short", "GET", "/vat/BE", http.StatusBadRequest, "invalid_number"},
+		{"vat-invalid-characters", "GET", "/vat/BE0836157420%3B", http.StatusBadRequest, "invalid_number"},
+		{"vat-service-error", "GET", "/vat/DE123456789", http.StatusServiceUnavailable, "vies_unavailable"},
+		{"iban-invalid", "GET", "/iban/539-0075470-35", http.StatusBadRequest, "invalid_account_number"},
+		{"iban-service-error", "GET", "/iban/539-0075470-34", http.StatusServiceUnavailable, "ibanbic_unavailable"},
+		{"method-not-allowed", "POST", "/rates", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"not-found", "GET", "/unknown", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			req, err := http.NewRequest(tc.method, api.URL+tc.path, nil)
+			assert.NoError(t, err, "request")
+
+			resp, err := http.DefaultClient.Do(req)
+			if !assert.NoError(t, err, "response") {
+				return
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode, "status")
+
+			if tc.expectedCode != "" {
+				var body apiError
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body), "json")
+				assert.Equal(t, tc.expectedCode, body.Code, "code")
+				assert.NotEmpty(t, body.Error, "error")
+			}
+
+		})
+	}
+
+	var conversion conversionResponse
+	getJSON(t, api.URL+"/rates/convert?amount=100&from=eur&to=jpy", &conversion)
+	assert.InDelta(t, 15552.0, conversion.Result, 0.000001, "convert-result")
+	assert.Equal(t, "JPY", conversion.To, "convert-to")
+	assert.Equal(t, "2024-01-03", conversion.Date, "convert-date")
+
+	var vat vatResponse
+	getJSON(t, api.URL+"/vat/BE0836157420", &vat)
+	assert.True(t, vat.Valid, "vat-valid")
+	assert.Equal(t, "SRL APPLE RETAIL BELGIUM", vat.Name, "vat-name")
+	assert.False(t, vat.CheckedAt.IsZero(), "vat-checked-at")
+
+	atomic.StoreInt32(&s.draining, 1)
+
+	resp, err := http.Get(api.URL + "/readyz")
+	if assert.NoError(t, err, "draining") {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "draining-status")
+	}
+
+}
+
+func TestServeReadyVATCache(t *testing.T) {
+
+	upstream := newTestServer()
+	defer upstream.Close()
+
+	cache := failingVATCache{}
+	s := newServer(finance.NewClient(
+		finance.WithRatesURL(upstream.URL+"/rates"),
+		finance.WithVATCache(finance.VATCachePolicy{Cache: cache}),
+	), cache, time.Hour)
+
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	resp, err := http.Get(api.URL + "/readyz")
+	if !assert.NoError(t, err, "response") {
+		return
+	}
+	defer resp.Body.Close()
+
+	var body apiError
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "status")
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body), "json")
+	assert.Equal(t, "vat_cache_unavailable", body.Code, "code")
+
+}
+
+func TestServeIBANCache(t *testing.T) {
+
+	ibanbic := financetest.NewIBANBICServer()
+	defer ibanbic.Close()
+
+	s := newServer(finance.NewClient(ibanbic.ClientOptions()...), finance.NewMemoryVATCache(), time.Hour)
+
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	var first, second ibanResponse
+	getJSON(t, api.URL+"/iban/738-1202561-74", &first)
+	requests := ibanbic.Requests()
+	getJSON(t, api.URL+"/iban/738.1202561.74", &second)
+
+	assert.Equal(t, "BE16 7381 2025 6174", first.IBAN, "iban")
+	assert.Equal(t, first, second, "cached")
+	assert.Equal(t, requests, ibanbic.Requests(), "requests")
+
+}
+
+func TestIBANCacheEviction(t *testing.T) {
+
+	ibanbic := financetest.NewIBANBICServer()
+	defer ibanbic.Close()
+
+	client := finance.NewClient(ibanbic.ClientOptions()...)
+	numbers := []string{"738-1202561-74", "001-2345678-03", "068-2222222-76"}
+
+	type test struct {
+		name     string
+		ttl      time.Duration
+		expected []string
+	}
+
+	var tests = []test{
+		{"oldest-when-full", time.Hour, []string{"001234567803", "068222222276"}},
+		{"expired", time.Nanosecond, []string{"068222222276"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			c := newIBANCache(tc.ttl, 2)
+			for _, number := range numbers {
+				_, err := c.lookup(context.Background(), client, number)
+				assert.NoError(t, err, "lookup")
+				time.Sleep(time.Millisecond)
+			}
+
+			var actual []string
+			for key := range c.entries {
+				actual = append(actual, key)
+			}
+			assert.ElementsMatch(t, tc.expected, actual, "entries")
+
+		})
+	}
+
+	c := newIBANCache(time.Nanosecond, 2)
+	c.lookup(context.Background(), client, numbers[0])
+	time.Sleep(time.Millisecond)
+	c.lookup(context.Background(), client, numbers[0])
+	assert.Len(t, c.entries, 1, "expired-on-lookup")
+
+}
+
+func getJSON(t *testing.T, url string, target interface{}) {
+
+	resp, err := http.Get(url)
+	if !assert.NoError(t, err, "get") {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "status")
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(target), "json")
+
+}
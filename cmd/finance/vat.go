@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// vatRecord is the result of checking a single VAT number
+type vatRecord struct {
+	Input       string `json:"input"`
+	Valid       bool   `json:"valid"`
+	CountryCode string `json:"country_code,omitempty"`
+	VATNumber   string `json:"vat_number,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// runVATCheck checks VAT numbers with VIES
+func runVATCheck(env *environment, args []string) int {
+
+	fs := env.flagSet("vat check", "[vat-number...]")
+	prevalidate := fs.Bool("prevalidate", false, "validate the numbers offline before checking them with VIES")
+	if !env.parse(fs, args) {
+		return exitUsage
+	}
+
+	inputs, err := env.inputs(fs.Args())
+	if err != nil {
+		return env.fail(exitUsage, err)
+	}
+
+	client := env.client(finance.WithVATPrevalidation(*prevalidate))
+
+	code := exitOK
+	records := make([]vatRecord, 0, len(inputs))
+	r := &report{columns: []string{"input", "valid", "country", "number", "name", "address", "error"}}
+
+	for _, input := range inputs {
+
+		record := vatRecord{Input: input}
+
+		info, err := client.CheckVATContext(env.ctx, input)
+		if err != nil {
+			record.Error = err.Error()
+			code = worse(code, vatExitCode(err))
+		} else {
+			record.Valid = info.IsValid
+			record.CountryCode = info.CountryCode
+			record.VATNumber = info.VATNumber
+			record.Name = info.Name
+			record.Address = info.Address
+			if !info.IsValid {
+				code = worse(code, exitInvalid)
+			}
+		}
+
+		records = append(records, record)
+		r.rows = append(r.rows, []string{
+			record.Input, strconv.FormatBool(record.Valid), record.CountryCode, record.VATNumber, record.Name, record.Address, record.Error,
+		})
+
+	}
+
+	r.records = records
+	if err := env.write(r); err != nil {
+		return env.fail(exitServiceError, err)
+	}
+
+	return code
+
+}
+
+// vatExitCode returns the exit code for an error returned when checking a VAT number
+func vatExitCode(err error) int {
+	if errors.Is(err, finance.ErrVATnumberNotValid) || errors.Is(err, finance.ErrVATNumberTooShort) {
+		return exitInvalid
+	}
+	return exitServiceError
+}
@@ -0,0 +1,331 @@
+package finance
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnknownCurrency is the error returned when a code isn't an ISO 4217 currency code
+	ErrUnknownCurrency = errors.New("Unknown ISO 4217 currency code")
+
+	// ErrCurrencyNotQuoted is the error returned when a currency is known but not quoted by the ECB
+	ErrCurrencyNotQuoted = errors.New("Currency is not quoted by the ECB")
+)
+
+// Currency describes a currency from the ISO 4217 list
+type Currency struct {
+	Code       string // The alphabetic code, e.g. EUR
+	Numeric    string // The numeric code, e.g. 978
+	MinorUnits int    // The number of decimals of the minor unit, -1 when not applicable (e.g. gold)
+	Name       string // The name of the currency
+	Active     bool   // Indicates the currency is still in use, false when it has been withdrawn
+}
+
+// currencyRegistry contains the current ISO 4217 currencies and the most common withdrawn ones
+var currencyRegistry = map[string]Currency{
+	"AED": {"AED", "784", 2, "UAE Dirham", true},
+	"AFN": {"AFN", "971", 2, "Afghani", true},
+	"ALL": {"ALL", "008", 2, "Lek", true},
+	"AMD": {"AMD", "051", 2, "Armenian Dram", true},
+	"ANG": {"ANG", "532", 2, "Netherlands Antillean Guilder", false},
+	"AOA": {"AOA", "973", 2, "Kwanza", true},
+	"ARS": {"ARS", "032", 2, "Argentine Peso", true},
+	"ATS": {"ATS", "040", 2, "Schilling", false},
+	"AUD": {"AUD", "036", 2, "Australian Dollar", true},
+	"AWG": {"AWG", "533", 2, "Aruban Florin", true},
+	"AZN": {"AZN", "944", 2, "Azerbaijan Manat", true},
+	"BAM": {"BAM", "977", 2, "Convertible Mark", true},
+	"BBD": {"BBD", "052", 2, "Barbados Dollar", true},
+	"BDT": {"BDT", "050", 2, "Taka", true},
+	"BEF": {"BEF", "056", 0, "Belgian Franc", false},
+	"BGL": {"BGL", "100", 2, "Lev", false},
+	"BGN": {"BGN", "975", 2, "Bulgarian Lev", true},
+	"BHD": {"BHD", "048", 3, "Bahraini Dinar", true},
+	"BIF": {"BIF", "108", 0, "Burundi Franc", true},
+	"BMD": {"BMD", "060", 2, "Bermudian Dollar", true},
+	"BND": {"BND", "096", 2, "Brunei Dollar", true},
+	"BOB": {"BOB", "068", 2, "Boliviano", true},
+	"BOV": {"BOV", "984", 2, "Mvdol", true},
+	"BRL": {"BRL", "986", 2, "Brazilian Real", true},
+	"BSD": {"BSD", "044", 2, "Bahamian Dollar", true},
+	"BTN": {"BTN", "064", 2, "Ngultrum", true},
+	"BWP": {"BWP", "072", 2, "Pula", true},
+	"BYN": {"BYN", "933", 2, "Belarusian Ruble", true},
+	"BYR": {"BYR", "974", 0, "Belarusian Ruble", false},
+	"BZD": {"BZD", "084", 2, "Belize Dollar", true},
+	"CAD": {"CAD", "124", 2, "Canadian Dollar", true},
+	"CDF": {"CDF", "976", 2, "Congolese Franc", true},
+	"CHE": {"CHE", "947", 2, "WIR Euro", true},
+	"CHF": {"CHF", "756", 2, "Swiss Franc", true},
+	"CHW": {"CHW", "948", 2, "WIR Franc", true},
+	"CLF": {"CLF", "990", 4, "Unidad de Fomento", true},
+	"CLP": {"CLP", "152", 0, "Chilean Peso", true},
+	"CNY": {"CNY", "156", 2, "Yuan Renminbi", true},
+	"COP": {"COP", "170", 2, "Colombian Peso", true},
+	"COU": {"COU", "970", 2, "Unidad de Valor Real", true},
+	"CRC": {"CRC", "188", 2, "Costa Rican Colon", true},
+	"CUC": {"CUC", "931", 2, "Peso Convertible", true},
+	"CUP": {"CUP", "192", 2, "Cuban Peso", true},
+	"CVE": {"CVE", "132", 2, "Cabo Verde Escudo", true},
+	"CYP": {"CYP", "196", 2, "Cyprus Pound", false},
+	"CZK": {"CZK", "203", 2, "Czech Koruna", true},
+	"DEM": {"DEM", "276", 2, "Deutsche Mark", false},
+	"DJF": {"DJF", "262", 0, "Djibouti Franc", true},
+	"DKK": {"DKK", "208", 2, "Danish Krone", true},
+	"DOP": {"DOP", "214", 2, "Dominican Peso", true},
+	"DZD": {"DZD", "012", 2, "Algerian Dinar", true},
+	"EEK": {"EEK", "233", 2, "Kroon", false},
+	"EGP": {"EGP", "818", 2, "Egyptian Pound", true},
+	"ERN": {"ERN", "232", 2, "Nakfa", true},
+	"ESP": {"ESP", "724", 0, "Spanish Peseta", false},
+	"ETB": {"ETB", "230", 2, "Ethiopian Birr", true},
+	"EUR": {"EUR", "978", 2, "Euro", true},
+	"FIM": {"FIM", "246", 2, "Markka", false},
+	"FJD": {"FJD", "242", 2, "Fiji Dollar", true},
+	"FKP": {"FKP", "238", 2, "Falkland Islands Pound", true},
+	"FRF": {"FRF", "250", 2, "French Franc", false},
+	"GBP": {"GBP", "826", 2, "Pound Sterling", true},
+	"GEL": {"GEL", "981", 2, "Lari", true},
+	"GHS": {"GHS", "936", 2, "Ghana Cedi", true},
+	"GIP": {"GIP", "292", 2, "Gibraltar Pound", true},
+	"GMD": {"GMD", "270", 2, "Dalasi", true},
+	"GNF": {"GNF", "324", 0, "Guinean Franc", true},
+	"GRD": {"GRD", "300", 0, "Drachma", false},
+	"GTQ": {"GTQ", "320", 2, "Quetzal", true},
+	"GYD": {"GYD", "328", 2, "Guyana Dollar", true},
+	"HKD": {"HKD", "344", 2, "Hong Kong Dollar", true},
+	"HNL": {"HNL", "340", 2, "Lempira", true},
+	"HRK": {"HRK", "191", 2, "Kuna", false},
+	"HTG": {"HTG", "332", 2, "Gourde", true},
+	"HUF": {"HUF", "348", 2, "Forint", true},
+	"IDR": {"IDR", "360", 2, "Rupiah", true},
+	"IEP": {"IEP", "372", 2, "Irish Pound", false},
+	"ILS": {"ILS", "376", 2, "New Israeli Sheqel", true},
+	"INR": {"INR", "356", 2, "Indian Rupee", true},
+	"IQD": {"IQD", "368", 3, "Iraqi Dinar", true},
+	"IRR": {"IRR", "364", 2, "Iranian Rial", true},
+	"ISK": {"ISK", "352", 0, "Iceland Krona", true},
+	"ITL": {"ITL", "380", 0, "Italian Lira", false},
+	"JMD": {"JMD", "388", 2, "Jamaican Dollar", true},
+	"JOD": {"JOD", "400", 3, "Jordanian Dinar", true},
+	"JPY": {"JPY", "392", 0, "Yen", true},
+	"KES": {"KES", "404", 2, "Kenyan Shilling", true},
+	"KGS": {"KGS", "417", 2, "Som", true},
+	"KHR": {"KHR", "116", 2, "Riel", true},
+	"KMF": {"KMF", "174", 0, "Comorian Franc", true},
+	"KPW": {"KPW", "408", 2, "North Korean Won", true},
+	"KRW": {"KRW", "410", 0, "Won", true},
+	"KWD": {"KWD", "414", 3, "Kuwaiti Dinar", true},
+	"KYD": {"KYD", "136", 2, "Cayman Islands Dollar", true},
+	"KZT": {"KZT", "398", 2, "Tenge", true},
+	"LAK": {"LAK", "418", 2, "Lao Kip", true},
+	"LBP": {"LBP", "422", 2, "Lebanese Pound", true},
+	"LKR": {"LKR", "144", 2, "Sri Lanka Rupee", true},
+	"LRD": {"LRD", "430", 2, "Liberian Dollar", true},
+	"LSL": {"LSL", "426", 2, "Loti", true},
+	"LTL": {"LTL", "440", 2, "Lithuanian Litas", false},
+	"LUF": {"LUF", "442", 0, "Luxembourg Franc", false},
+	"LVL": {"LVL", "428", 2, "Latvian Lats", false},
+	"LYD": {"LYD", "434", 3, "Libyan Dinar", true},
+	"MAD": {"MAD", "504", 2, "Moroccan Dirham", true},
+	"MDL": {"MDL", "498", 2, "Moldovan Leu", true},
+	"MGA": {"MGA", "969", 2, "Malagasy Ariary", true},
+	"MKD": {"MKD", "807", 2, "Denar", true},
+	"MMK": {"MMK", "104", 2, "Kyat", true},
+	"MNT": {"MNT", "496", 2, "Tugrik", true},
+	"MOP": {"MOP", "446", 2, "Pataca", true},
+	"MRO": {"MRO", "478", 2, "Ouguiya", false},
+	"MRU": {"MRU", "929", 2, "Ouguiya", true},
+	"MTL": {"MTL", "470", 2, "Maltese Lira", false},
+	"MUR": {"MUR", "480", 2, "Mauritius Rupee", true},
+	"MVR": {"MVR", "462", 2, "Rufiyaa", true},
+	"MWK": {"MWK", "454", 2, "Malawi Kwacha", true},
+	"MXN": {"MXN", "484", 2, "Mexican Peso", true},
+	"MXV": {"MXV", "979", 2, "Mexican Unidad de Inversion (UDI)", true},
+	"MYR": {"MYR", "458", 2, "Malaysian Ringgit", true},
+	"MZN": {"MZN", "943", 2, "Mozambique Metical", true},
+	"NAD": {"NAD", "516", 2, "Namibia Dollar", true},
+	"NGN": {"NGN", "566", 2, "Naira", true},
+	"NIO": {"NIO", "558", 2, "Cordoba Oro", true},
+	"NLG": {"NLG", "528", 2, "Netherlands Guilder", false},
+	"NOK": {"NOK", "578", 2, "Norwegian Krone", true},
+	"NPR": {"NPR", "524", 2, "Nepalese Rupee", true},
+	"NZD": {"NZD", "554", 2, "New Zealand Dollar", true},
+	"OMR": {"OMR", "512", 3, "Rial Omani", true},
+	"PAB": {"PAB", "590", 2, "Balboa", true},
+	"PEN": {"PEN", "604", 2, "Sol", true},
+	"PGK": {"PGK", "598", 2, "Kina", true},
+	"PHP": {"PHP", "608", 2, "Philippine Peso", true},
+	"PKR": {"PKR", "586", 2, "Pakistan Rupee", true},
+	"PLN": {"PLN", "985", 2, "Zloty", true},
+	"PTE": {"PTE", "620", 0, "Portuguese Escudo", false},
+	"PYG": {"PYG", "600", 0, "Guarani", true},
+	"QAR": {"QAR", "634", 2, "Qatari Rial", true},
+	"ROL": {"ROL", "642", 2, "Leu", false},
+	"RON": {"RON", "946", 2, "Romanian Leu", true},
+	"RSD": {"RSD", "941", 2, "Serbian Dinar", true},
+	"RUB": {"RUB", "643", 2, "Russian Ruble", true},
+	"RWF": {"RWF", "646", 0, "Rwanda Franc", true},
+	"SAR": {"SAR", "682", 2, "Saudi Riyal", true},
+	"SBD": {"SBD", "090", 2, "Solomon Islands Dollar", true},
+	"SCR": {"SCR", "690", 2, "Seychelles Rupee", true},
+	"SDG": {"SDG", "938", 2, "Sudanese Pound", true},
+	"SEK": {"SEK", "752", 2, "Swedish Krona", true},
+	"SGD": {"SGD", "702", 2, "Singapore Dollar", true},
+	"SHP": {"SHP", "654", 2, "Saint Helena Pound", true},
+	"SIT": {"SIT", "705", 2, "Tolar", false},
+	"SKK": {"SKK", "703", 2, "Slovak Koruna", false},
+	"SLE": {"SLE", "925", 2, "Leone", true},
+	"SLL": {"SLL", "694", 2, "Leone", false},
+	"SOS": {"SOS", "706", 2, "Somali Shilling", true},
+	"SRD": {"SRD", "968", 2, "Surinam Dollar", true},
+	"SSP": {"SSP", "728", 2, "South Sudanese Pound", true},
+	"STD": {"STD", "678", 2, "Dobra", false},
+	"STN": {"STN", "930", 2, "Dobra", true},
+	"SVC": {"SVC", "222", 2, "El Salvador Colon", true},
+	"SYP": {"SYP", "760", 2, "Syrian Pound", true},
+	"SZL": {"SZL", "748", 2, "Lilangeni", true},
+	"THB": {"THB", "764", 2, "Baht", true},
+	"TJS": {"TJS", "972", 2, "Somoni", true},
+	"TMT": {"TMT", "934", 2, "Turkmenistan New Manat", true},
+	"TND": {"TND", "788", 3, "Tunisian Dinar", true},
+	"TOP": {"TOP", "776", 2, "Pa'anga", true},
+	"TRL": {"TRL", "792", 0, "Turkish Lira", false},
+	"TRY": {"TRY", "949", 2, "Turkish Lira", true},
+	"TTD": {"TTD", "780", 2, "Trinidad and Tobago Dollar", true},
+	"TWD": {"TWD", "901", 2, "New Taiwan Dollar", true},
+	"TZS": {"TZS", "834", 2, "Tanzanian Shilling", true},
+	"UAH": {"UAH", "980", 2, "Hryvnia", true},
+	"UGX": {"UGX", "800", 0, "Uganda Shilling", true},
+	"USD": {"USD", "840", 2, "US Dollar", true},
+	"USN": {"USN", "997", 2, "US Dollar (Next day)", true},
+	"UYI": {"UYI", "940", 0, "Uruguay Peso en Unidades Indexadas (UI)", true},
+	"UYU": {"UYU", "858", 2, "Peso Uruguayo", true},
+	"UYW": {"UYW", "927", 4, "Unidad Previsional", true},
+	"UZS": {"UZS", "860", 2, "Uzbekistan Sum", true},
+	"VED": {"VED", "926", 2, "Bolivar Soberano", true},
+	"VEF": {"VEF", "937", 2, "Bolivar", false},
+	"VES": {"VES", "928", 2, "Bolivar Soberano", true},
+	"VND": {"VND", "704", 0, "Dong", true},
+	"VUV": {"VUV", "548", 0, "Vatu", true},
+	"WST": {"WST", "882", 2, "Tala", true},
+	"XAF": {"XAF", "950", 0, "CFA Franc BEAC", true},
+	"XAG": {"XAG", "961", -1, "Silver", true},
+	"XAU": {"XAU", "959", -1, "Gold", true},
+	"XBA": {"XBA", "955", -1, "Bond Markets Unit European Composite Unit (EURCO)", true},
+	"XBB": {"XBB", "956", -1, "Bond Markets Unit European Monetary Unit (E.M.U.-6)", true},
+	"XBC": {"XBC", "957", -1, "Bond Markets Unit European Unit of Account 9 (E.U.A.-9)", true},
+	"XBD": {"XBD", "958", -1, "Bond Markets Unit European Unit of Account 17 (E.U.A.-17)", true},
+	"XCD": {"XCD", "951", 2, "East Caribbean Dollar", true},
+	"XCG": {"XCG", "532", 2, "Caribbean Guilder", true},
+	"XDR": {"XDR", "960", -1, "SDR (Special Drawing Right)", true},
+	"XOF": {"XOF", "952", 0, "CFA Franc BCEAO", true},
+	"XPD": {"XPD", "964", -1, "Palladium", true},
+	"XPF": {"XPF", "953", 0, "CFP Franc", true},
+	"XPT": {"XPT", "962", -1, "Platinum", true},
+	"XSU": {"XSU", "994", -1, "Sucre", true},
+	"XTS": {"XTS", "963", -1, "Codes specifically reserved for testing purposes", true},
+	"XUA": {"XUA", "965", -1, "ADB Unit of Account", true},
+	"XXX": {"XXX", "999", -1, "The codes assigned for transactions where no currency is involved", true},
+	"YER": {"YER", "886", 2, "Yemeni Rial", true},
+	"ZAR": {"ZAR", "710", 2, "Rand", true},
+	"ZMK": {"ZMK", "894", 2, "Zambian Kwacha", false},
+	"ZMW": {"ZMW", "967", 2, "Zambian Kwacha", true},
+	"ZWG": {"ZWG", "924", 2, "Zimbabwe Gold", true},
+	"ZWL": {"ZWL", "932", 2, "Zimbabwe Dollar", false},
+}
+
+// LookupCurrency returns the currency with the given alphabetic code
+//
+// The code is matched case-insensitively. ErrUnknownCurrency is returned for codes which aren't in the ISO 4217 list.
+func LookupCurrency(code string) (Currency, error) {
+
+	currency, ok := currencyRegistry[normalizeCurrencyCode(code)]
+	if !ok {
+		return Currency{}, errors.Wrap(ErrUnknownCurrency, code)
+	}
+
+	return currency, nil
+
+}
+
+// LookupCurrencyByNumeric returns the currency with the given numeric code
+//
+// When a numeric code was reused, the active currency is returned.
+func LookupCurrencyByNumeric(numeric string) (Currency, error) {
+
+	numeric = leftPad(strings.TrimSpace(numeric), 3, '0')
+
+	var result *Currency
+	for _, currency := range currencyRegistry {
+		if currency.Numeric != numeric {
+			continue
+		}
+		if result == nil || (currency.Active && !result.Active) {
+			c := currency
+			result = &c
+		}
+	}
+
+	if result == nil {
+		return Currency{}, errors.Wrap(ErrUnknownCurrency, numeric)
+	}
+
+	return *result, nil
+
+}
+
+// Currencies returns all known currencies sorted by code
+func Currencies() []Currency {
+
+	result := make([]Currency, 0, len(currencyRegistry))
+	for _, currency := range currencyRegistry {
+		result = append(result, currency)
+	}
+
+	sort.Slice(result, func(i int, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+
+	return result
+
+}
+
+// NormalizeCurrency returns the uppercase alphabetic code of a currency, or ErrUnknownCurrency when it isn't known
+func NormalizeCurrency(code string) (string, error) {
+
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return "", err
+	}
+
+	return currency.Code, nil
+
+}
+
+// lookupRate returns the rate of a currency from a rates map
+//
+// It tells unknown currency codes apart from currencies which aren't quoted.
+func lookupRate(rates map[string]float64, code string) (string, float64, error) {
+
+	currency, ok := currencyRegistry[normalizeCurrencyCode(code)]
+	if !ok {
+		return "", 0, ErrUnknownCurrency
+	}
+
+	rate, ok := rates[currency.Code]
+	if !ok {
+		return "", 0, ErrCurrencyNotQuoted
+	}
+
+	return currency.Code, rate, nil
+
+}
+
+// normalizeCurrencyCode trims and uppercases a currency code
+func normalizeCurrencyCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,80 @@
+package finance_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestLookupCurrency(t *testing.T) {
+
+	type test struct {
+		code          string
+		expected      finance.Currency
+		expectedError error
+	}
+
+	var tests = []test{
+		{"EUR", finance.Currency{Code: "EUR", Numeric: "978", MinorUnits: 2, Name: "Euro", Active: true}, nil},
+		{" jpy ", finance.Currency{Code: "JPY", Numeric: "392", MinorUnits: 0, Name: "Yen", Active: true}, nil},
+		{"KWD", finance.Currency{Code: "KWD", Numeric: "414", MinorUnits: 3, Name: "Kuwaiti Dinar", Active: true}, nil},
+		{"XAU", finance.Currency{Code: "XAU", Numeric: "959", MinorUnits: -1, Name: "Gold", Active: true}, nil},
+		{"BEF", finance.Currency{Code: "BEF", Numeric: "056", MinorUnits: 0, Name: "Belgian Franc", Active: false}, nil},
+		{"ABC", finance.Currency{}, finance.ErrUnknownCurrency},
+		{"", finance.Currency{}, finance.ErrUnknownCurrency},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.code, func(t *testing.T) {
+
+			actual, err := finance.LookupCurrency(tc.code)
+
+			if tc.expectedError != nil {
+				assert.True(t, errors.Is(err, tc.expectedError), "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			assert.Equal(t, tc.expected, actual, "currency")
+
+		})
+	}
+
+}
+
+func TestLookupCurrencyByNumeric(t *testing.T) {
+
+	actual, err := finance.LookupCurrencyByNumeric("978")
+	assert.NoError(t, err, "error")
+	assert.Equal(t, "EUR", actual.Code, "eur")
+
+	actual, err = finance.LookupCurrencyByNumeric("36")
+	assert.NoError(t, err, "padded-error")
+	assert.Equal(t, "AUD", actual.Code, "padded")
+
+	actual, err = finance.LookupCurrencyByNumeric("532")
+	assert.NoError(t, err, "reused-error")
+	assert.Equal(t, "XCG", actual.Code, "reused")
+
+	_, err = finance.LookupCurrencyByNumeric("000")
+	assert.True(t, errors.Is(err, finance.ErrUnknownCurrency), "unknown")
+
+}
+
+func TestCurrencies(t *testing.T) {
+
+	currencies := finance.Currencies()
+
+	assert.NotEmpty(t, currencies, "currencies")
+	for i := 1; i < len(currencies); i++ {
+		assert.True(t, currencies[i-1].Code < currencies[i].Code, "sorted")
+	}
+
+	code, err := finance.NormalizeCurrency("usd")
+	assert.NoError(t, err, "normalize-error")
+	assert.Equal(t, "USD", code, "normalize")
+
+}
@@ -0,0 +1,271 @@
+package finance
+
+import (
+	"context"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RoundingMode defines how a decimal value is rounded
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value and ties to the even neighbour, also known as banker's rounding
+	RoundHalfEven RoundingMode = iota
+
+	// RoundHalfUp rounds to the nearest value and ties away from zero
+	RoundHalfUp
+
+	// RoundDown truncates towards zero
+	RoundDown
+)
+
+// rateDisplayPrecision is the number of decimals used to show a cross rate which isn't rounded
+const rateDisplayPrecision = 12
+
+// ErrInvalidDecimal is the error returned when a value isn't a valid decimal number
+var ErrInvalidDecimal = errors.New("Invalid decimal number")
+
+// decimalPattern matches a plain decimal number such as 1.0876 or -12
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// DecimalOptions defines the precision and rounding of a decimal conversion
+type DecimalOptions struct {
+	Precision     int          // The number of decimals of the result
+	Rounding      RoundingMode // The rounding mode for the result and the cross rate
+	RatePrecision int          // When greater than zero, the cross rate is rounded to this number of decimals before it's applied
+}
+
+// DecimalConversion contains the result of a decimal currency conversion and the rates it used
+type DecimalConversion struct {
+	Value     string    // The value which was converted
+	From      string    // The currency which was converted from
+	To        string    // The currency which was converted to
+	FromRate  string    // The published rate of From
+	ToRate    string    // The published rate of To
+	Rate      string    // The cross rate, rounded to 12 decimals when RatePrecision isn't set
+	Result    string    // The converted value, with exactly Precision decimals
+	Date      time.Time // The publication date of the rates which were used
+	Base      string    // The base currency of the rates which were used
+	Source    string    // The URL the rates were fetched from
+	Provider  string    // The name of the provider which returned the rates
+	FetchedAt time.Time // The time the rates were fetched
+}
+
+// String returns the name of the rounding mode
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half-even"
+	case RoundHalfUp:
+		return "half-up"
+	case RoundDown:
+		return "down"
+	}
+	return "RoundingMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// ConvertDecimal converts a decimal value from one currency to another using the published rates without any
+// floating-point error
+func ConvertDecimal(value string, from string, to string, opts DecimalOptions) (*DecimalConversion, error) {
+	return defaultClient().ConvertDecimal(value, from, to, opts)
+}
+
+// ConvertDecimalContext converts a decimal value from one currency to another using the given context
+func ConvertDecimalContext(ctx context.Context, value string, from string, to string, opts DecimalOptions) (*DecimalConversion, error) {
+	return defaultClient().ConvertDecimalContext(ctx, value, from, to, opts)
+}
+
+// ConvertDecimal converts a decimal value from one currency to another using the published rates without any
+// floating-point error
+func (c *Client) ConvertDecimal(value string, from string, to string, opts DecimalOptions) (*DecimalConversion, error) {
+	return c.ConvertDecimalContext(context.Background(), value, from, to, opts)
+}
+
+// ConvertDecimalContext converts a decimal value from one currency to another using the given context
+func (c *Client) ConvertDecimalContext(ctx context.Context, value string, from string, to string, opts DecimalOptions) (*DecimalConversion, error) {
+
+	table, err := c.LatestExchangeRatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.ConvertDecimal(value, from, to, opts)
+
+}
+
+// ConvertDecimal converts a decimal value from one currency to another using the rates of the given date
+func (h *HistoricalRates) ConvertDecimal(value string, from string, to string, date time.Time, opts DecimalOptions) (*DecimalConversion, error) {
+
+	table, err := h.TableOn(date)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.ConvertDecimal(value, from, to, opts)
+
+}
+
+// ConvertDecimal converts a decimal value from one currency to another using the rates in the table
+//
+// The rates are used exactly as they were published. The cross rate is the rate of to divided by the rate of from,
+// which is optionally rounded to RatePrecision decimals. The result is rounded to Precision decimals.
+func (t *ExchangeRateTable) ConvertDecimal(value string, from string, to string, opts DecimalOptions) (*DecimalConversion, error) {
+
+	amount, err := parseDecimal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	fromCode, fromRate, err := t.decimalRate(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid from currency: "+from)
+	}
+
+	toCode, toRate, err := t.decimalRate(to)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid to currency: "+to)
+	}
+
+	rate := new(big.Rat).Quo(toRate, fromRate)
+	ratePrecision := rateDisplayPrecision
+	if opts.RatePrecision > 0 {
+		rate = roundDecimal(rate, opts.RatePrecision, opts.Rounding)
+		ratePrecision = opts.RatePrecision
+	}
+
+	result := roundDecimal(new(big.Rat).Mul(amount, rate), opts.Precision, opts.Rounding)
+
+	return &DecimalConversion{
+		Value:     strings.TrimSpace(value),
+		From:      fromCode,
+		To:        toCode,
+		FromRate:  t.decimalRateString(fromCode),
+		ToRate:    t.decimalRateString(toCode),
+		Rate:      formatRate(rate, ratePrecision, opts),
+		Result:    result.FloatString(maxInt(opts.Precision, 0)),
+		Date:      t.Date,
+		Base:      t.Base,
+		Source:    t.Source,
+		Provider:  t.Provider,
+		FetchedAt: t.FetchedAt,
+	}, nil
+
+}
+
+// RoundDecimal rounds a decimal number to the given number of decimals
+func RoundDecimal(value string, precision int, mode RoundingMode) (string, error) {
+
+	r, err := parseDecimal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return roundDecimal(r, precision, mode).FloatString(maxInt(precision, 0)), nil
+
+}
+
+// decimalRate returns the normalized code and the exact rate of a currency
+//
+// Rates which aren't positive are rejected, as conversions would divide by them.
+func (t *ExchangeRateTable) decimalRate(code string) (string, *big.Rat, error) {
+
+	normalized, _, err := lookupRate(t.Rates, code)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rate, err := parseDecimal(t.decimalRateString(normalized))
+	if err != nil || rate.Sign() <= 0 {
+		return "", nil, errors.Wrap(ErrInvalidRatesDocument, "invalid rate for "+normalized)
+	}
+
+	return normalized, rate, nil
+
+}
+
+// decimalRateString returns the rate of a currency as published
+//
+// Tables without published rates use the shortest decimal representation of the float rate.
+func (t *ExchangeRateTable) decimalRateString(code string) string {
+	if rate, ok := t.DecimalRates[code]; ok {
+		return rate
+	}
+	return strconv.FormatFloat(t.Rates[code], 'f', -1, 64)
+}
+
+// formatRate formats a cross rate, trimming the trailing zeros when it wasn't rounded explicitly
+func formatRate(rate *big.Rat, precision int, opts DecimalOptions) string {
+
+	formatted := roundDecimal(rate, precision, opts.Rounding).FloatString(precision)
+	if opts.RatePrecision > 0 {
+		return formatted
+	}
+
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+
+}
+
+// parseDecimal parses a plain decimal number into an exact rational number
+func parseDecimal(value string) (*big.Rat, error) {
+
+	value = strings.TrimSpace(value)
+	if !decimalPattern.MatchString(value) {
+		return nil, errors.Wrap(ErrInvalidDecimal, value)
+	}
+
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidDecimal, value)
+	}
+
+	return r, nil
+
+}
+
+// roundDecimal rounds a rational number to the given number of decimals
+func roundDecimal(r *big.Rat, precision int, mode RoundingMode) *big.Rat {
+	scale := pow10Rat(maxInt(precision, 0))
+	scaled := new(big.Rat).Mul(r, scale)
+	rounded := new(big.Rat).SetInt(roundRational(scaled, mode))
+	return rounded.Quo(rounded, scale)
+}
+
+// roundRational rounds a rational number to an integer
+func roundRational(r *big.Rat, mode RoundingMode) *big.Int {
+
+	num := new(big.Int).Abs(r.Num())
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 && mode != RoundDown {
+		switch rem.Mul(rem, big.NewInt(2)).Cmp(r.Denom()) {
+		case 1:
+			quo.Add(quo, big.NewInt(1))
+		case 0:
+			if mode == RoundHalfUp || quo.Bit(0) == 1 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+
+	return quo
+
+}
+
+// maxInt returns the largest of two integers
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
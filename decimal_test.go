@@ -0,0 +1,124 @@
+package finance_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestRoundDecimal(t *testing.T) {
+
+	type test struct {
+		name      string
+		value     string
+		precision int
+		mode      finance.RoundingMode
+		expected  string
+	}
+
+	var tests = []test{
+		{"half-even-tie-down", "0.125", 2, finance.RoundHalfEven, "0.12"},
+		{"half-even-tie-up", "0.135", 2, finance.RoundHalfEven, "0.14"},
+		{"half-even-above", "0.1251", 2, finance.RoundHalfEven, "0.13"},
+		{"half-up-tie", "0.125", 2, finance.RoundHalfUp, "0.13"},
+		{"half-up-below", "0.1249", 2, finance.RoundHalfUp, "0.12"},
+		{"down", "0.129", 2, finance.RoundDown, "0.12"},
+		{"negative-half-even", "-0.125", 2, finance.RoundHalfEven, "-0.12"},
+		{"negative-half-up", "-0.125", 2, finance.RoundHalfUp, "-0.13"},
+		{"negative-down", "-0.129", 2, finance.RoundDown, "-0.12"},
+		{"float-error", "1.005", 2, finance.RoundHalfUp, "1.01"},
+		{"padded", "3", 2, finance.RoundHalfEven, "3.00"},
+		{"zero-decimals", "2.5", 0, finance.RoundHalfEven, "2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := finance.RoundDecimal(tc.value, tc.precision, tc.mode)
+			assert.NoError(t, err, "error")
+			assert.Equal(t, tc.expected, actual, "rounded")
+		})
+	}
+
+	for _, value := range []string{"", "abc", "1/3", "1e3", "1.2.3"} {
+		_, err := finance.RoundDecimal(value, 2, finance.RoundHalfEven)
+		assert.True(t, errors.Is(err, finance.ErrInvalidDecimal), "invalid-"+value)
+	}
+
+}
+
+func TestConvertDecimal(t *testing.T) {
+
+	s := newDailyRatesServer()
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithRatesURL(s.URL))
+
+	type test struct {
+		name         string
+		value        string
+		from         string
+		to           string
+		opts         finance.DecimalOptions
+		expectedRate string
+		expected     string
+	}
+
+	var tests = []test{
+		{"eur-usd", "1000.00", "EUR", "USD", finance.DecimalOptions{Precision: 2}, "1.0919", "1091.90"},
+		{"usd-eur", "1091.90", "usd", "eur", finance.DecimalOptions{Precision: 2}, "0.915834783405", "1000.00"},
+		{"usd-gbp", "1000", "USD", "GBP", finance.DecimalOptions{Precision: 2}, "0.792361937906", "792.36"},
+		{"usd-gbp-rate-precision", "1000", "USD", "GBP", finance.DecimalOptions{Precision: 2, RatePrecision: 4}, "0.7924", "792.40"},
+		{"eur-jpy-half-even", "0.50", "EUR", "JPY", finance.DecimalOptions{Precision: 0, Rounding: finance.RoundHalfEven}, "155.52", "78"},
+		{"eur-jpy-down", "0.50", "EUR", "JPY", finance.DecimalOptions{Precision: 0, Rounding: finance.RoundDown}, "155.52", "77"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			conversion, err := client.ConvertDecimal(tc.value, tc.from, tc.to, tc.opts)
+
+			assert.NoError(t, err, "error")
+			if assert.NotNil(t, conversion, "conversion") {
+				assert.Equal(t, tc.expectedRate, conversion.Rate, "rate")
+				assert.Equal(t, tc.expected, conversion.Result, "result")
+				assert.Equal(t, "2024-01-03", conversion.Date.Format("2006-01-02"), "date")
+			}
+
+		})
+	}
+
+	conversion, err := client.ConvertDecimal("1", "EUR", "USD", finance.DecimalOptions{})
+	if assert.NoError(t, err, "published-error") {
+		assert.Equal(t, "1", conversion.FromRate, "from-rate")
+		assert.Equal(t, "1.0919", conversion.ToRate, "to-rate")
+	}
+
+	_, err = client.ConvertDecimal("abc", "EUR", "USD", finance.DecimalOptions{})
+	assert.True(t, errors.Is(err, finance.ErrInvalidDecimal), "invalid-value")
+
+	_, err = client.ConvertDecimal("1", "EUR", "CHF", finance.DecimalOptions{})
+	assert.True(t, errors.Is(err, finance.ErrCurrencyNotQuoted), "not-quoted")
+
+}
+
+func TestConvertDecimalZeroRate(t *testing.T) {
+
+	rates := &finance.ExchangeRateTable{
+		Base:         finance.BaseCurrency,
+		Rates:        map[string]float64{"EUR": 1, "USD": 0, "GBP": -0.86518},
+		DecimalRates: map[string]string{"EUR": "1", "USD": "0", "GBP": "-0.86518"},
+	}
+
+	_, err := rates.ConvertDecimal("1", "USD", "EUR", finance.DecimalOptions{Precision: 2})
+	assert.True(t, errors.Is(err, finance.ErrInvalidRatesDocument), "zero-from")
+
+	_, err = rates.ConvertDecimal("1", "EUR", "USD", finance.DecimalOptions{Precision: 2})
+	assert.True(t, errors.Is(err, finance.ErrInvalidRatesDocument), "zero-to")
+
+	_, err = rates.ConvertDecimal("1", "GBP", "EUR", finance.DecimalOptions{Precision: 2})
+	assert.True(t, errors.Is(err, finance.ErrInvalidRatesDocument), "negative")
+
+}
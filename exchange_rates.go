@@ -1,83 +1,245 @@
 package finance
 
 import (
+	"context"
 	"encoding/xml"
-	"errors"
-	"io/ioutil"
-	"net/http"
 	"strings"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // DefaultRatesURL defines the default URL to fetch the exchange rates from
 const DefaultRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
 
 // RatesURL is the URL where to fetch the rates from
+//
+// It's used by the package-level functions. Use NewClient with WithRatesURL to configure a client of your own.
 var RatesURL = DefaultRatesURL
 
+// DefaultRatesTimeout is the default timeout to use when fetching exchange rates
+const DefaultRatesTimeout = 5 * time.Second
+
 // DefaultTimeout is the default tiemout for the HTTP client
-var DefaultTimeout = 5 * time.Second
+var DefaultTimeout = DefaultRatesTimeout
+
+// BaseCurrency is the currency in which the ECB expresses its reference rates
+const BaseCurrency = "EUR"
+
+// ExchangeRateTable contains the exchange rates together with the metadata describing them
+type ExchangeRateTable struct {
+	Date      time.Time          // The publication date of the rates
+	Base      string             // The base currency of the rates
+	Source    string             // The URL the rates were fetched from
+	Provider  string             // The name of the provider which returned the rates
+	FetchedAt time.Time          // The time the rates were fetched
+	Rates     map[string]float64 // The rates, keyed by currency
+
+	DecimalRates map[string]string // The rates exactly as published, keyed by currency, used by ConvertDecimal
+}
+
+// Conversion contains the result of a currency conversion and the rates it used
+type Conversion struct {
+	Value     float64   // The value which was converted
+	From      string    // The currency which was converted from
+	To        string    // The currency which was converted to
+	Rate      float64   // The rate which was applied to go from From to To
+	Result    float64   // The converted value
+	Date      time.Time // The publication date of the rates which were used
+	Base      string    // The base currency of the rates which were used
+	Source    string    // The URL the rates were fetched from
+	Provider  string    // The name of the provider which returned the rates
+	FetchedAt time.Time // The time the rates were fetched
+}
 
 // ExchangeRates returs the list exchange rates
 func ExchangeRates() (map[string]float64, error) {
+	return defaultClient().ExchangeRates()
+}
 
-	var rates exchangeRate
+// ExchangeRatesContext returs the list exchange rates using the given context
+func ExchangeRatesContext(ctx context.Context) (map[string]float64, error) {
+	return defaultClient().ExchangeRatesContext(ctx)
+}
 
-	ratesMap := make(map[string]float64, 0)
+// LatestExchangeRates returns the latest exchange rates including their publication date
+func LatestExchangeRates() (*ExchangeRateTable, error) {
+	return defaultClient().LatestExchangeRates()
+}
 
-	client := &http.Client{}
-	client.Timeout = DefaultTimeout
+// LatestExchangeRatesContext returns the latest exchange rates including their publication date using the given context
+func LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error) {
+	return defaultClient().LatestExchangeRatesContext(ctx)
+}
 
-	resp, err := client.Get(RatesURL)
-	if err != nil {
-		return ratesMap, err
-	}
-	defer resp.Body.Close()
+// ConvertRate converts a value from once exchange rate to another
+func ConvertRate(value float64, from string, to string) (float64, error) {
+	return defaultClient().ConvertRate(value, from, to)
+}
 
-	rawData, err := ioutil.ReadAll(resp.Body)
+// ConvertRateContext converts a value from once exchange rate to another using the given context
+func ConvertRateContext(ctx context.Context, value float64, from string, to string) (float64, error) {
+	return defaultClient().ConvertRateContext(ctx, value, from, to)
+}
+
+// Convert converts a value from one currency to another and returns the rates it used
+func Convert(value float64, from string, to string) (*Conversion, error) {
+	return defaultClient().Convert(value, from, to)
+}
+
+// ConvertContext converts a value from one currency to another using the given context and returns the rates it used
+func ConvertContext(ctx context.Context, value float64, from string, to string) (*Conversion, error) {
+	return defaultClient().ConvertContext(ctx, value, from, to)
+}
+
+// ExchangeRates returs the list exchange rates
+func (c *Client) ExchangeRates() (map[string]float64, error) {
+	return c.ExchangeRatesContext(context.Background())
+}
+
+// ExchangeRatesContext returs the list exchange rates using the given context
+func (c *Client) ExchangeRatesContext(ctx context.Context) (map[string]float64, error) {
+
+	table, err := c.LatestExchangeRatesContext(ctx)
 	if err != nil {
-		return ratesMap, err
+		return make(map[string]float64, 0), err
 	}
 
-	err = xml.Unmarshal(rawData, &rates)
+	return table.Rates, nil
+
+}
+
+// LatestExchangeRates returns the latest exchange rates including their publication date
+func (c *Client) LatestExchangeRates() (*ExchangeRateTable, error) {
+	return c.LatestExchangeRatesContext(context.Background())
+}
+
+// LatestExchangeRatesContext returns the latest exchange rates including their publication date using the given context
+func (c *Client) LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error) {
+
+	fetchedAt := time.Now()
+
+	rates, err := c.fetchExchangeRates(ctx, c.ratesURL)
 	if err != nil {
-		return ratesMap, err
+		return nil, err
 	}
 
-	ratesMap["EUR"] = 1
+	table := &ExchangeRateTable{
+		Base:      BaseCurrency,
+		Source:    c.ratesURL,
+		Provider:  ECBProviderName,
+		FetchedAt: fetchedAt,
+		Rates:     map[string]float64{BaseCurrency: 1},
+
+		DecimalRates: map[string]string{BaseCurrency: "1"},
+	}
 
 	for _, cube := range rates.Cubes {
 		for _, timedCube := range cube.TimedCubes {
+			if date, err := time.Parse(rateDateLayout, timedCube.Time); err == nil {
+				table.Date = date
+			}
 			for _, rate := range timedCube.Rates {
-				ratesMap[strings.ToUpper(rate.Currency)] = rate.Rate
+				value, err := rate.value()
+				if err != nil {
+					return nil, err
+				}
+				currency := strings.ToUpper(rate.Currency)
+				table.Rates[currency] = value
+				table.DecimalRates[currency] = strings.TrimSpace(rate.Rate)
 			}
 		}
 	}
 
-	return ratesMap, nil
+	return table, nil
 
 }
 
 // ConvertRate converts a value from once exchange rate to another
-func ConvertRate(value float64, from string, to string) (float64, error) {
+func (c *Client) ConvertRate(value float64, from string, to string) (float64, error) {
+	return c.ConvertRateContext(context.Background(), value, from, to)
+}
 
-	rates, err := ExchangeRates()
+// ConvertRateContext converts a value from once exchange rate to another using the given context
+func (c *Client) ConvertRateContext(ctx context.Context, value float64, from string, to string) (float64, error) {
+
+	conversion, err := c.ConvertContext(ctx, value, from, to)
 	if err != nil {
 		return 0, err
 	}
 
-	fromRate, ok := rates[from]
-	if !ok {
-		return 0, errors.New("Invalid from currency: " + from)
+	return conversion.Result, nil
+
+}
+
+// Convert converts a value from one currency to another and returns the rates it used
+func (c *Client) Convert(value float64, from string, to string) (*Conversion, error) {
+	return c.ConvertContext(context.Background(), value, from, to)
+}
+
+// ConvertContext converts a value from one currency to another using the given context and returns the rates it used
+func (c *Client) ConvertContext(ctx context.Context, value float64, from string, to string) (*Conversion, error) {
+
+	table, err := c.LatestExchangeRatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.Convert(value, from, to)
+
+}
+
+// Convert converts a value from one currency to another using the rates in the table
+func (t *ExchangeRateTable) Convert(value float64, from string, to string) (*Conversion, error) {
+
+	fromCode, fromRate, err := lookupRate(t.Rates, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid from currency: "+from)
+	}
+
+	toCode, toRate, err := lookupRate(t.Rates, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid to currency: "+to)
+	}
+
+	return &Conversion{
+		Value:     value,
+		From:      fromCode,
+		To:        toCode,
+		Rate:      toRate / fromRate,
+		Result:    value / fromRate * toRate,
+		Date:      t.Date,
+		Base:      t.Base,
+		Source:    t.Source,
+		Provider:  t.Provider,
+		FetchedAt: t.FetchedAt,
+	}, nil
+
+}
+
+// fetchExchangeRates downloads and parses an ECB exchange rates document
+func (c *Client) fetchExchangeRates(ctx context.Context, url string) (*exchangeRate, error) {
+
+	var rates exchangeRate
+
+	rawData, err := c.doRequest(ctx, c.ratesTimeout, "GET", url, "", nil, retryOnServerError)
+	if err != nil {
+		if unreachable, ok := err.(*unreachableError); ok {
+			return nil, unreachable.err
+		}
+		return nil, err
 	}
 
-	toRate, ok := rates[to]
-	if !ok {
-		return 0, errors.New("Invalid to currency: " + to)
+	err = xml.Unmarshal(rawData, &rates)
+	if err != nil {
+		return nil, err
 	}
 
-	result := value / fromRate * toRate
+	// A well-formed document without any day of rates is as useless as a broken one
+	if !rates.hasRates() {
+		return nil, errors.Wrap(ErrInvalidRatesDocument, "no rates found")
+	}
 
-	return result, nil
+	return &rates, nil
 
 }
@@ -0,0 +1,251 @@
+package finance
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ecbPublicationHour is the hour (in Frankfurt time) around which the ECB publishes its reference rates
+const ecbPublicationHour = 16
+
+// DefaultStaleRatesRetryInterval is the default interval after which rates are fetched again when the ECB
+// hasn't published the rates we expected yet
+const DefaultStaleRatesRetryInterval = 5 * time.Minute
+
+// The offsets of Frankfurt, in which the ECB publishes its reference rates
+var (
+	cetZone  = time.FixedZone("CET", 60*60)
+	cestZone = time.FixedZone("CEST", 2*60*60)
+)
+
+// CachedRates keeps the last fetched exchange rates of a provider in memory and serves conversions from them
+//
+// The rates are only fetched again after the next expected ECB publication. Concurrent callers which need
+// fresh rates share a single request. A CachedRates is safe for concurrent use.
+type CachedRates struct {
+	StaleRetryInterval time.Duration // The interval after which to retry when the fetched rates are older than expected
+
+	provider RateProvider
+
+	mu        sync.Mutex
+	table     *ExchangeRateTable
+	expiresAt time.Time
+	refresh   *ratesRefresh
+}
+
+// ratesRefresh is a fetch of the exchange rates which is shared by all callers waiting for it
+type ratesRefresh struct {
+	done  chan struct{}
+	table *ExchangeRateTable
+	err   error
+}
+
+// NewCachedRates returns a new cache which fetches the exchange rates from the given provider
+//
+// When provider is nil, the rates are fetched from the ECB using a client with the default settings.
+func NewCachedRates(provider RateProvider) *CachedRates {
+	if provider == nil {
+		provider = NewClient()
+	}
+	return &CachedRates{
+		StaleRetryInterval: DefaultStaleRatesRetryInterval,
+		provider:           provider,
+	}
+}
+
+// ExchangeRates returs the list exchange rates
+func (r *CachedRates) ExchangeRates() (map[string]float64, error) {
+	return r.ExchangeRatesContext(context.Background())
+}
+
+// ExchangeRatesContext returs the list exchange rates using the given context
+//
+// The result is a copy, so changing it doesn't affect the cache.
+func (r *CachedRates) ExchangeRatesContext(ctx context.Context) (map[string]float64, error) {
+
+	table, err := r.LatestExchangeRatesContext(ctx)
+	if err != nil {
+		return make(map[string]float64, 0), err
+	}
+
+	result := make(map[string]float64, len(table.Rates))
+	for currency, rate := range table.Rates {
+		result[currency] = rate
+	}
+
+	return result, nil
+
+}
+
+// LatestExchangeRates returns the cached exchange rates, fetching them when they are expired
+//
+// The returned table is shared between callers and should not be modified.
+func (r *CachedRates) LatestExchangeRates() (*ExchangeRateTable, error) {
+	return r.LatestExchangeRatesContext(context.Background())
+}
+
+// LatestExchangeRatesContext returns the cached exchange rates, fetching them when they are expired
+//
+// The context only limits how long the caller waits, the shared fetch itself is bound by the provider timeouts.
+// The returned table is shared between callers and should not be modified.
+func (r *CachedRates) LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error) {
+
+	r.mu.Lock()
+
+	if r.table != nil && time.Now().Before(r.expiresAt) {
+		table := r.table
+		r.mu.Unlock()
+		return table, nil
+	}
+
+	refresh := r.refresh
+	if refresh == nil {
+		refresh = &ratesRefresh{done: make(chan struct{})}
+		r.refresh = refresh
+		go r.doRefresh(refresh)
+	}
+
+	r.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-refresh.done:
+		return refresh.table, refresh.err
+	}
+
+}
+
+// ConvertRate converts a value from once exchange rate to another
+func (r *CachedRates) ConvertRate(value float64, from string, to string) (float64, error) {
+	return r.ConvertRateContext(context.Background(), value, from, to)
+}
+
+// ConvertRateContext converts a value from once exchange rate to another using the given context
+func (r *CachedRates) ConvertRateContext(ctx context.Context, value float64, from string, to string) (float64, error) {
+
+	conversion, err := r.ConvertContext(ctx, value, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return conversion.Result, nil
+
+}
+
+// Convert converts a value from one currency to another and returns the rates it used
+func (r *CachedRates) Convert(value float64, from string, to string) (*Conversion, error) {
+	return r.ConvertContext(context.Background(), value, from, to)
+}
+
+// ConvertContext converts a value from one currency to another using the given context and returns the rates it used
+func (r *CachedRates) ConvertContext(ctx context.Context, value float64, from string, to string) (*Conversion, error) {
+
+	table, err := r.LatestExchangeRatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.Convert(value, from, to)
+
+}
+
+// ExpiresAt returns the time after which the cached rates will be fetched again
+func (r *CachedRates) ExpiresAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.expiresAt
+}
+
+// doRefresh fetches the exchange rates and stores them in the cache
+func (r *CachedRates) doRefresh(refresh *ratesRefresh) {
+
+	table, err := r.provider.LatestExchangeRatesContext(context.Background())
+
+	r.mu.Lock()
+	if err == nil {
+		r.table = table
+		r.expiresAt = r.expiryFor(table)
+	}
+	r.refresh = nil
+	r.mu.Unlock()
+
+	refresh.table = table
+	refresh.err = err
+	close(refresh.done)
+
+}
+
+// expiryFor returns the time after which the given table should be fetched again
+func (r *CachedRates) expiryFor(table *ExchangeRateTable) time.Time {
+
+	lastPublication := previousRatesPublication(table.FetchedAt)
+	lastPublicationDate := time.Date(lastPublication.Year(), lastPublication.Month(), lastPublication.Day(), 0, 0, 0, 0, time.UTC)
+
+	if table.Date.Before(lastPublicationDate) {
+		return table.FetchedAt.Add(r.StaleRetryInterval)
+	}
+
+	return NextRatesPublication(table.FetchedAt)
+
+}
+
+// NextRatesPublication returns the first moment after t at which the ECB is expected to publish new rates
+func NextRatesPublication(t time.Time) time.Time {
+
+	candidate := ecbPublicationOn(t.In(centralEuropeanZone(t)))
+	for !candidate.After(t) || !isRatesPublicationDay(candidate) {
+		candidate = ecbPublicationOn(candidate.AddDate(0, 0, 1))
+	}
+
+	return candidate
+
+}
+
+// previousRatesPublication returns the last moment at or before t at which the ECB was expected to publish rates
+func previousRatesPublication(t time.Time) time.Time {
+
+	candidate := ecbPublicationOn(t.In(centralEuropeanZone(t)))
+	for candidate.After(t) || !isRatesPublicationDay(candidate) {
+		candidate = ecbPublicationOn(candidate.AddDate(0, 0, -1))
+	}
+
+	return candidate
+
+}
+
+// isRatesPublicationDay returns true when the ECB publishes reference rates on the given day
+func isRatesPublicationDay(t time.Time) bool {
+	return IsTARGETBusinessDay(t)
+}
+
+// ecbPublicationOn returns the moment at which the ECB publishes its rates on the day of t in Frankfurt time
+func ecbPublicationOn(t time.Time) time.Time {
+	noon := time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, time.UTC)
+	return time.Date(t.Year(), t.Month(), t.Day(), ecbPublicationHour, 0, 0, 0, centralEuropeanZone(noon))
+}
+
+// centralEuropeanZone returns the offset of Frankfurt at the given moment
+//
+// Summer time starts on the last Sunday of March and ends on the last Sunday of October, both at 01:00 UTC. The
+// offset is computed instead of loaded, so the publication time is also right on systems without time zone data.
+func centralEuropeanZone(t time.Time) *time.Location {
+
+	t = t.UTC()
+	if !t.Before(lastSundayOf(t.Year(), time.March)) && t.Before(lastSundayOf(t.Year(), time.October)) {
+		return cestZone
+	}
+
+	return cetZone
+
+}
+
+// lastSundayOf returns 01:00 UTC on the last Sunday of the given month
+func lastSundayOf(year int, month time.Month) time.Time {
+	t := time.Date(year, month+1, 1, 1, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	for t.Weekday() != time.Sunday {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
@@ -0,0 +1,175 @@
+package finance_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestNextRatesPublication(t *testing.T) {
+
+	frankfurt, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data is not available")
+	}
+
+	type test struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}
+
+	var tests = []test{
+		{"wednesday-morning", time.Date(2024, 1, 3, 10, 0, 0, 0, frankfurt), time.Date(2024, 1, 3, 16, 0, 0, 0, frankfurt)},
+		{"wednesday-evening", time.Date(2024, 1, 3, 17, 0, 0, 0, frankfurt), time.Date(2024, 1, 4, 16, 0, 0, 0, frankfurt)},
+		{"exactly-at-publication", time.Date(2024, 1, 3, 16, 0, 0, 0, frankfurt), time.Date(2024, 1, 4, 16, 0, 0, 0, frankfurt)},
+		{"friday-evening", time.Date(2024, 1, 5, 17, 0, 0, 0, frankfurt), time.Date(2024, 1, 8, 16, 0, 0, 0, frankfurt)},
+		{"saturday", time.Date(2024, 1, 6, 12, 0, 0, 0, frankfurt), time.Date(2024, 1, 8, 16, 0, 0, 0, frankfurt)},
+		{"easter", time.Date(2024, 3, 28, 17, 0, 0, 0, frankfurt), time.Date(2024, 4, 2, 16, 0, 0, 0, frankfurt)},
+		{"christmas", time.Date(2024, 12, 24, 17, 0, 0, 0, frankfurt), time.Date(2024, 12, 27, 16, 0, 0, 0, frankfurt)},
+		{"utc-input", time.Date(2024, 1, 3, 15, 30, 0, 0, time.UTC), time.Date(2024, 1, 4, 16, 0, 0, 0, frankfurt)},
+		{"summer-time", time.Date(2024, 7, 3, 13, 30, 0, 0, time.UTC), time.Date(2024, 7, 3, 14, 0, 0, 0, time.UTC)},
+		{"summer-time-after-publication", time.Date(2024, 7, 3, 14, 30, 0, 0, time.UTC), time.Date(2024, 7, 4, 14, 0, 0, 0, time.UTC)},
+		{"summer-time-starts", time.Date(2023, 3, 24, 16, 0, 0, 0, time.UTC), time.Date(2023, 3, 27, 14, 0, 0, 0, time.UTC)},
+		{"summer-time-ends", time.Date(2024, 10, 25, 14, 30, 0, 0, time.UTC), time.Date(2024, 10, 28, 15, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := finance.NextRatesPublication(tc.now)
+			assert.True(t, tc.expected.Equal(actual), "expected %v, got %v", tc.expected, actual)
+		})
+	}
+
+}
+
+func TestCachedRatesSingleFetch(t *testing.T) {
+
+	var requests int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	cache := finance.NewCachedRates(finance.NewClient(finance.WithRatesURL(s.URL)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			actual, err := cache.ConvertRate(2, "EUR", "USD")
+			assert.NoError(t, err, "error")
+			assert.Equal(t, 2.1838, actual, "actual")
+		}()
+	}
+	wg.Wait()
+
+	_, err := cache.ConvertRate(1, "USD", "GBP")
+	assert.NoError(t, err, "error")
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "requests")
+
+}
+
+func TestCachedRatesExchangeRatesCopy(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	cache := finance.NewCachedRates(finance.NewClient(finance.WithRatesURL(s.URL)))
+
+	rates, err := cache.ExchangeRates()
+	assert.NoError(t, err, "error")
+	rates["USD"] = 99
+	delete(rates, "GBP")
+
+	actual, err := cache.ExchangeRates()
+	assert.NoError(t, err, "error")
+	assert.Equal(t, 1.0919, actual["USD"], "usd")
+	assert.Contains(t, actual, "GBP", "gbp")
+
+}
+
+func TestCachedRatesStaleRetry(t *testing.T) {
+
+	var requests int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	cache := finance.NewCachedRates(finance.NewClient(finance.WithRatesURL(s.URL)))
+	cache.StaleRetryInterval = time.Nanosecond
+
+	_, err := cache.LatestExchangeRates()
+	assert.NoError(t, err, "error")
+
+	time.Sleep(time.Millisecond)
+
+	_, err = cache.LatestExchangeRates()
+	assert.NoError(t, err, "error")
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests), "requests")
+
+}
+
+func TestCachedRatesExpiresAtNextPublication(t *testing.T) {
+
+	today := time.Now().In(loadFrankfurt()).Format("2006-01-02")
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(strings.Replace(dailyRatesXML, "2024-01-03", today, 1)))
+		}),
+	)
+	defer s.Close()
+
+	cache := finance.NewCachedRates(finance.NewClient(finance.WithRatesURL(s.URL)))
+
+	table, err := cache.LatestExchangeRates()
+	assert.NoError(t, err, "error")
+
+	assert.True(t, cache.ExpiresAt().Equal(finance.NextRatesPublication(table.FetchedAt)), "expires-at")
+
+}
+
+func TestCachedRatesError(t *testing.T) {
+
+	cache := finance.NewCachedRates(finance.NewClient(finance.WithRatesURL("ht&@-tp://:aa")))
+
+	rates, err := cache.ExchangeRates()
+
+	assert.Error(t, err, "error")
+	assert.Empty(t, rates, "rates")
+	assert.True(t, cache.ExpiresAt().IsZero(), "expires-at")
+
+}
+
+func loadFrankfurt() *time.Location {
+	if loc, err := time.LoadLocation("Europe/Berlin"); err == nil {
+		return loc
+	}
+	return time.FixedZone("CET", 60*60)
+}
@@ -0,0 +1,200 @@
+package finance
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CrossRates contains the mid rates between each pair of currencies of an exchange rate table
+type CrossRates struct {
+	Date       time.Time // The publication date of the rates
+	Base       string    // The base currency of the rates the matrix was built from
+	Source     string    // The URL the rates were fetched from
+	Provider   string    // The name of the provider which returned the rates
+	FetchedAt  time.Time // The time the rates were fetched
+	Currencies []string  // The currencies in the matrix, sorted alphabetically
+
+	rates  map[string]float64 // The rates against the base currency
+	index  map[string]int     // The position of each currency in the matrix
+	matrix [][]float64        // The cross rates, matrix[i][j] converts one unit of Currencies[i] into Currencies[j]
+}
+
+// LatestCrossRates returns the cross rates built from the latest exchange rates
+func LatestCrossRates() (*CrossRates, error) {
+	return defaultClient().LatestCrossRates()
+}
+
+// LatestCrossRatesContext returns the cross rates built from the latest exchange rates using the given context
+func LatestCrossRatesContext(ctx context.Context) (*CrossRates, error) {
+	return defaultClient().LatestCrossRatesContext(ctx)
+}
+
+// LatestCrossRates returns the cross rates built from the latest exchange rates
+func (c *Client) LatestCrossRates() (*CrossRates, error) {
+	return c.LatestCrossRatesContext(context.Background())
+}
+
+// LatestCrossRatesContext returns the cross rates built from the latest exchange rates using the given context
+func (c *Client) LatestCrossRatesContext(ctx context.Context) (*CrossRates, error) {
+
+	table, err := c.LatestExchangeRatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.CrossRates(), nil
+
+}
+
+// CrossRates builds the matrix with the mid rates between each pair of currencies in the table
+func (t *ExchangeRateTable) CrossRates() *CrossRates {
+
+	m := &CrossRates{
+		Date:      t.Date,
+		Base:      t.Base,
+		Source:    t.Source,
+		Provider:  t.Provider,
+		FetchedAt: t.FetchedAt,
+		rates:     make(map[string]float64, len(t.Rates)),
+		index:     make(map[string]int, len(t.Rates)),
+	}
+
+	for currency, rate := range t.Rates {
+		if rate <= 0 {
+			continue
+		}
+		m.rates[currency] = rate
+		m.Currencies = append(m.Currencies, currency)
+	}
+	sort.Strings(m.Currencies)
+
+	m.matrix = make([][]float64, len(m.Currencies))
+	for i, from := range m.Currencies {
+		m.index[from] = i
+		m.matrix[i] = make([]float64, len(m.Currencies))
+		for j, to := range m.Currencies {
+			if i == j {
+				m.matrix[i][j] = 1
+				continue
+			}
+			m.matrix[i][j] = m.rates[to] / m.rates[from]
+		}
+	}
+
+	return m
+
+}
+
+// Rate returns the mid rate to convert one unit of from into to
+func (m *CrossRates) Rate(from string, to string) (float64, error) {
+
+	i, err := m.position(from)
+	if err != nil {
+		return 0, errors.Wrap(err, "Invalid from currency: "+from)
+	}
+
+	j, err := m.position(to)
+	if err != nil {
+		return 0, errors.Wrap(err, "Invalid to currency: "+to)
+	}
+
+	return m.matrix[i][j], nil
+
+}
+
+// Inverse returns the inverse of the mid rate from from to to, which is the rate to convert one unit of to into from
+func (m *CrossRates) Inverse(from string, to string) (float64, error) {
+	return m.Rate(to, from)
+}
+
+// Matrix returns a copy of the matrix, the rows and columns are ordered like Currencies
+func (m *CrossRates) Matrix() [][]float64 {
+	result := make([][]float64, len(m.matrix))
+	for i, row := range m.matrix {
+		result[i] = append([]float64(nil), row...)
+	}
+	return result
+}
+
+// WriteCSV writes the matrix as CSV
+//
+// The first row and the first column contain the currencies. Each cell converts one unit of the currency of its row
+// into the currency of its column.
+func (m *CrossRates) WriteCSV(w io.Writer) error {
+
+	writer := csv.NewWriter(w)
+
+	header := append([]string{""}, m.Currencies...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for i, from := range m.Currencies {
+		record := make([]string, 0, len(m.Currencies)+1)
+		record = append(record, from)
+		for _, rate := range m.matrix[i] {
+			record = append(record, strconv.FormatFloat(rate, 'f', -1, 64))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+
+}
+
+// MarshalJSON returns the matrix as JSON, with the rates keyed by the from and the to currency
+func (m *CrossRates) MarshalJSON() ([]byte, error) {
+
+	rates := make(map[string]map[string]float64, len(m.Currencies))
+	for i, from := range m.Currencies {
+		row := make(map[string]float64, len(m.Currencies))
+		for j, to := range m.Currencies {
+			row[to] = m.matrix[i][j]
+		}
+		rates[from] = row
+	}
+
+	var date string
+	if !m.Date.IsZero() {
+		date = m.Date.Format(rateDateLayout)
+	}
+
+	return json.Marshal(struct {
+		Date       string                        `json:"date,omitempty"`
+		Base       string                        `json:"base"`
+		Source     string                        `json:"source,omitempty"`
+		Provider   string                        `json:"provider,omitempty"`
+		Currencies []string                      `json:"currencies"`
+		Rates      map[string]map[string]float64 `json:"rates"`
+	}{
+		Date:       date,
+		Base:       m.Base,
+		Source:     m.Source,
+		Provider:   m.Provider,
+		Currencies: m.Currencies,
+		Rates:      rates,
+	})
+
+}
+
+// position returns the position of a currency in the matrix
+func (m *CrossRates) position(code string) (int, error) {
+
+	normalized, _, err := lookupRate(m.rates, code)
+	if err != nil {
+		return 0, err
+	}
+
+	return m.index[normalized], nil
+
+}
@@ -0,0 +1,90 @@
+package finance_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestCrossRates(t *testing.T) {
+
+	s := newDailyRatesServer()
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithRatesURL(s.URL))
+
+	matrix, err := client.LatestCrossRates()
+	if !assert.NoError(t, err, "error") {
+		return
+	}
+
+	assert.Equal(t, []string{"EUR", "GBP", "JPY", "USD"}, matrix.Currencies, "currencies")
+	assert.Equal(t, "2024-01-03", matrix.Date.Format("2006-01-02"), "date")
+
+	type test struct {
+		name          string
+		from          string
+		to            string
+		expected      float64
+		expectedError error
+	}
+
+	var tests = []test{
+		{"eur-usd", "EUR", "USD", 1.0919, nil},
+		{"usd-eur", "USD", "EUR", 1 / 1.0919, nil},
+		{"usd-jpy", "usd", "jpy", 155.52 / 1.0919, nil},
+		{"gbp-gbp", "GBP", "GBP", 1, nil},
+		{"unknown", "ABC", "USD", 0, finance.ErrUnknownCurrency},
+		{"not-quoted", "USD", "CHF", 0, finance.ErrCurrencyNotQuoted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			actual, err := matrix.Rate(tc.from, tc.to)
+
+			if tc.expectedError != nil {
+				assert.True(t, errors.Is(err, tc.expectedError), "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			assert.InDelta(t, tc.expected, actual, 1e-12, "rate")
+
+			inverse, err := matrix.Inverse(tc.from, tc.to)
+			assert.NoError(t, err, "inverse-error")
+			assert.InDelta(t, 1/tc.expected, inverse, 1e-12, "inverse")
+
+		})
+	}
+
+}
+
+func TestCrossRatesExport(t *testing.T) {
+
+	table := &finance.ExchangeRateTable{
+		Base:  finance.BaseCurrency,
+		Rates: map[string]float64{"EUR": 1, "USD": 2},
+	}
+
+	matrix := table.CrossRates()
+
+	var buf bytes.Buffer
+	assert.NoError(t, matrix.WriteCSV(&buf), "csv-error")
+	assert.Equal(t, ",EUR,USD\nEUR,1,2\nUSD,0.5,1\n", buf.String(), "csv")
+
+	rawData, err := json.Marshal(matrix)
+	assert.NoError(t, err, "json-error")
+	assert.JSONEq(t, `{"base":"EUR","currencies":["EUR","USD"],"rates":{"EUR":{"EUR":1,"USD":2},"USD":{"EUR":0.5,"USD":1}}}`, string(rawData), "json")
+
+	rows := matrix.Matrix()
+	rows[0][1] = 99
+	actual, _ := matrix.Rate("EUR", "USD")
+	assert.Equal(t, 2.0, actual, "copy")
+
+}
@@ -0,0 +1,322 @@
+package finance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultHistoricalRatesURL defines the default URL to fetch the exchange rates of the last 90 days from
+const DefaultHistoricalRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml"
+
+// DefaultFullHistoricalRatesURL defines the default URL to fetch all historical exchange rates from
+const DefaultFullHistoricalRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+
+// HistoricalRatesURL is the URL where to fetch the rates of the last 90 days from
+//
+// It's used by the package-level functions. Use NewClient with WithHistoricalRatesURL to configure a client of your own.
+var HistoricalRatesURL = DefaultHistoricalRatesURL
+
+// FullHistoricalRatesURL is the URL where to fetch all historical rates from
+//
+// It's used by the package-level functions. Use NewClient with WithFullHistoricalRatesURL to configure a client of your own.
+var FullHistoricalRatesURL = DefaultFullHistoricalRatesURL
+
+// rateDateLayout is the layout of the time attribute used by the ECB
+const rateDateLayout = "2006-01-02"
+
+// maxRateLookupDays is the number of days a lookup policy searches for published rates
+const maxRateLookupDays = 10
+
+var (
+	// ErrNoRatesForDate is the error returned when no rates were published on the requested date
+	ErrNoRatesForDate = errors.New("No exchange rates available for date")
+
+	// ErrInvalidRateDate is the error returned when the ECB feed contains an unparseable date
+	ErrInvalidRateDate = errors.New("Invalid exchange rate date")
+)
+
+// RateLookupPolicy defines which rates are used for a date on which no rates were published
+type RateLookupPolicy int
+
+const (
+	// LookupExact only uses the rates published on the requested date and returns ErrNoRatesForDate otherwise
+	LookupExact RateLookupPolicy = iota
+
+	// LookupPreviousBusinessDay uses the rates of the last TARGET business day before the requested date
+	LookupPreviousBusinessDay
+
+	// LookupNextBusinessDay uses the rates of the first TARGET business day after the requested date
+	LookupNextBusinessDay
+)
+
+// HistoricalRates contains the exchange rates indexed by their publication date
+type HistoricalRates struct {
+	Source       string           // The URL the rates were fetched from
+	FetchedAt    time.Time        // The time the rates were fetched
+	LookupPolicy RateLookupPolicy // Which rates to use for a date without rates, LookupExact by default
+
+	dates        []time.Time                   // The publication dates, sorted ascending
+	rates        map[string]map[string]float64 // The rates, keyed by date and currency
+	decimalRates map[string]map[string]string  // The rates exactly as published, keyed by date and currency
+}
+
+// HistoricalExchangeRates returns the exchange rates of the last 90 days
+func HistoricalExchangeRates() (*HistoricalRates, error) {
+	return defaultClient().HistoricalExchangeRates()
+}
+
+// HistoricalExchangeRatesContext returns the exchange rates of the last 90 days using the given context
+func HistoricalExchangeRatesContext(ctx context.Context) (*HistoricalRates, error) {
+	return defaultClient().HistoricalExchangeRatesContext(ctx)
+}
+
+// FullHistoricalExchangeRates returns all exchange rates published since 1999
+func FullHistoricalExchangeRates() (*HistoricalRates, error) {
+	return defaultClient().FullHistoricalExchangeRates()
+}
+
+// FullHistoricalExchangeRatesContext returns all exchange rates published since 1999 using the given context
+func FullHistoricalExchangeRatesContext(ctx context.Context) (*HistoricalRates, error) {
+	return defaultClient().FullHistoricalExchangeRatesContext(ctx)
+}
+
+// HistoricalExchangeRates returns the exchange rates of the last 90 days
+func (c *Client) HistoricalExchangeRates() (*HistoricalRates, error) {
+	return c.HistoricalExchangeRatesContext(context.Background())
+}
+
+// HistoricalExchangeRatesContext returns the exchange rates of the last 90 days using the given context
+func (c *Client) HistoricalExchangeRatesContext(ctx context.Context) (*HistoricalRates, error) {
+	return c.fetchHistoricalRates(ctx, c.historicalRatesURL)
+}
+
+// FullHistoricalExchangeRates returns all exchange rates published since 1999
+func (c *Client) FullHistoricalExchangeRates() (*HistoricalRates, error) {
+	return c.FullHistoricalExchangeRatesContext(context.Background())
+}
+
+// FullHistoricalExchangeRatesContext returns all exchange rates published since 1999 using the given context
+func (c *Client) FullHistoricalExchangeRatesContext(ctx context.Context) (*HistoricalRates, error) {
+	return c.fetchHistoricalRates(ctx, c.fullHistoricalRatesURL)
+}
+
+// Dates returns the publication dates in the table, sorted ascending
+func (h *HistoricalRates) Dates() []time.Time {
+	dates := make([]time.Time, len(h.dates))
+	copy(dates, h.dates)
+	return dates
+}
+
+// PublicationDate returns the publication date of the rates which are used for the given date
+//
+// It applies the lookup policy when no rates were published on the date itself.
+func (h *HistoricalRates) PublicationDate(date time.Time) (time.Time, error) {
+
+	requested := date.Format(rateDateLayout)
+	if _, ok := h.rates[requested]; ok {
+		return time.Parse(rateDateLayout, requested)
+	}
+
+	if h.LookupPolicy == LookupExact || len(h.dates) == 0 {
+		return time.Time{}, errors.Wrap(ErrNoRatesForDate, requested)
+	}
+
+	candidate, _ := time.Parse(rateDateLayout, requested)
+	limit := candidate.AddDate(0, 0, -maxRateLookupDays)
+	if h.LookupPolicy == LookupNextBusinessDay {
+		limit = candidate.AddDate(0, 0, maxRateLookupDays)
+	}
+
+	for {
+
+		if h.LookupPolicy == LookupNextBusinessDay {
+			candidate = NextTARGETBusinessDay(candidate)
+			if candidate.After(limit) {
+				break
+			}
+		} else {
+			candidate = PreviousTARGETBusinessDay(candidate)
+			if candidate.Before(limit) {
+				break
+			}
+		}
+
+		if _, ok := h.rates[candidate.Format(rateDateLayout)]; ok {
+			return candidate, nil
+		}
+
+	}
+
+	return time.Time{}, errors.Wrap(ErrNoRatesForDate, requested)
+
+}
+
+// RatesOn returns the exchange rates which were published on the given date
+//
+// The lookup policy decides which rates are returned when nothing was published on the date itself.
+func (h *HistoricalRates) RatesOn(date time.Time) (map[string]float64, error) {
+
+	publishedOn, err := h.PublicationDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	dayRates := h.rates[publishedOn.Format(rateDateLayout)]
+
+	result := make(map[string]float64, len(dayRates))
+	for currency, rate := range dayRates {
+		result[currency] = rate
+	}
+
+	return result, nil
+
+}
+
+// TableOn returns the exchange rates published on the given date including their metadata
+//
+// The date of the table is the publication date which was actually used according to the lookup policy.
+func (h *HistoricalRates) TableOn(date time.Time) (*ExchangeRateTable, error) {
+
+	publishedOn, err := h.PublicationDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	key := publishedOn.Format(rateDateLayout)
+
+	dayRates := make(map[string]float64, len(h.rates[key]))
+	for currency, rate := range h.rates[key] {
+		dayRates[currency] = rate
+	}
+
+	decimalRates := make(map[string]string, len(h.decimalRates[key]))
+	for currency, rate := range h.decimalRates[key] {
+		decimalRates[currency] = rate
+	}
+
+	return &ExchangeRateTable{
+		Date:      publishedOn,
+		Base:      BaseCurrency,
+		Source:    h.Source,
+		Provider:  ECBProviderName,
+		FetchedAt: h.FetchedAt,
+		Rates:     dayRates,
+
+		DecimalRates: decimalRates,
+	}, nil
+
+}
+
+// Rate returns the rate for a currency which was published on the given date
+func (h *HistoricalRates) Rate(currency string, date time.Time) (float64, error) {
+
+	dayRates, err := h.RatesOn(date)
+	if err != nil {
+		return 0, err
+	}
+
+	_, rate, err := lookupRate(dayRates, currency)
+	if err != nil {
+		return 0, errors.Wrap(err, "Invalid currency: "+currency)
+	}
+
+	return rate, nil
+
+}
+
+// ConvertRate converts a value from one currency to another using the rates of the given date
+func (h *HistoricalRates) ConvertRate(value float64, from string, to string, date time.Time) (float64, error) {
+
+	conversion, err := h.Convert(value, from, to, date)
+	if err != nil {
+		return 0, err
+	}
+
+	return conversion.Result, nil
+
+}
+
+// Convert converts a value from one currency to another using the rates of the given date
+func (h *HistoricalRates) Convert(value float64, from string, to string, date time.Time) (*Conversion, error) {
+
+	table, err := h.TableOn(date)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.Convert(value, from, to)
+
+}
+
+// fetchHistoricalRates downloads and parses an ECB historical exchange rates document
+func (c *Client) fetchHistoricalRates(ctx context.Context, url string) (*HistoricalRates, error) {
+
+	fetchedAt := time.Now()
+
+	rates, err := c.fetchExchangeRates(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := newHistoricalRates(rates)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Source = url
+	result.FetchedAt = fetchedAt
+
+	return result, nil
+
+}
+
+// newHistoricalRates builds the date-indexed table from a parsed ECB document
+func newHistoricalRates(rates *exchangeRate) (*HistoricalRates, error) {
+
+	result := &HistoricalRates{
+		rates:        make(map[string]map[string]float64),
+		decimalRates: make(map[string]map[string]string),
+	}
+
+	for _, cube := range rates.Cubes {
+		for _, timedCube := range cube.TimedCubes {
+
+			date, err := time.Parse(rateDateLayout, timedCube.Time)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidRateDate, timedCube.Time)
+			}
+
+			key := date.Format(rateDateLayout)
+
+			dayRates, ok := result.rates[key]
+			if !ok {
+				dayRates = map[string]float64{BaseCurrency: 1}
+				result.rates[key] = dayRates
+				result.decimalRates[key] = map[string]string{BaseCurrency: "1"}
+				result.dates = append(result.dates, date)
+			}
+
+			for _, rate := range timedCube.Rates {
+				value, err := rate.value()
+				if err != nil {
+					return nil, err
+				}
+				currency := strings.ToUpper(rate.Currency)
+				dayRates[currency] = value
+				result.decimalRates[key][currency] = strings.TrimSpace(rate.Rate)
+			}
+
+		}
+	}
+
+	sort.Slice(result.dates, func(i, j int) bool {
+		return result.dates[i].Before(result.dates[j])
+	})
+
+	return result, nil
+
+}
@@ -0,0 +1,248 @@
+package finance_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+const historicalRatesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2024-01-03">
+			<Cube currency="USD" rate="1.0919"/>
+			<Cube currency="JPY" rate="155.52"/>
+			<Cube currency="GBP" rate="0.86518"/>
+		</Cube>
+		<Cube time="2024-01-02">
+			<Cube currency="USD" rate="1.0956"/>
+			<Cube currency="JPY" rate="155.07"/>
+			<Cube currency="GBP" rate="0.86565"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestHistoricalExchangeRates(t *testing.T) {
+
+	s := newHistoricalRatesServer(historicalRatesXML)
+	defer s.Close()
+
+	finance.HistoricalRatesURL = s.URL
+	defer resetHistoricalRatesURL()
+
+	rates, err := finance.HistoricalExchangeRates()
+	assert.NoError(t, err, "error")
+	assert.NotNil(t, rates, "rates")
+
+	dates := rates.Dates()
+	if assert.Len(t, dates, 2, "dates") {
+		assert.Equal(t, "2024-01-02", dates[0].Format("2006-01-02"), "first-date")
+		assert.Equal(t, "2024-01-03", dates[1].Format("2006-01-02"), "last-date")
+	}
+
+	type test struct {
+		name         string
+		currency     string
+		date         time.Time
+		expected     float64
+		expectsError bool
+	}
+
+	var tests = []test{
+		{"usd-first", "USD", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 1.0956, false},
+		{"usd-last", "USD", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), 1.0919, false},
+		{"lowercase", "jpy", time.Date(2024, 1, 3, 15, 30, 0, 0, time.UTC), 155.52, false},
+		{"eur", "EUR", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 1, false},
+		{"unknown-currency", "XXX", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 0, true},
+		{"unknown-date", "USD", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), 0, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			actual, err := rates.Rate(tc.currency, tc.date)
+
+			if tc.expectsError {
+				assert.Zero(t, actual, "actual")
+				assert.Error(t, err, "error")
+			} else {
+				assert.NoError(t, err, "error")
+				assert.Equal(t, tc.expected, actual, "actual")
+			}
+
+		})
+	}
+
+}
+
+func TestHistoricalExchangeRatesConvert(t *testing.T) {
+
+	s := newHistoricalRatesServer(historicalRatesXML)
+	defer s.Close()
+
+	finance.FullHistoricalRatesURL = s.URL
+	defer resetHistoricalRatesURL()
+
+	rates, err := finance.FullHistoricalExchangeRates()
+	assert.NoError(t, err, "error")
+
+	actual, err := rates.ConvertRate(10.956, "USD", "EUR", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err, "error")
+	assert.InDelta(t, 10, actual, 0.0001, "actual")
+
+	conversion, err := rates.Convert(10.956, "USD", "EUR", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err, "error")
+	assert.Equal(t, "2024-01-02", conversion.Date.Format("2006-01-02"), "date")
+	assert.Equal(t, s.URL, conversion.Source, "source")
+
+	_, err = rates.ConvertRate(1, "USD", "EUR", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, finance.ErrNoRatesForDate, "error")
+
+}
+
+func TestHistoricalExchangeRatesTableOnCopy(t *testing.T) {
+
+	s := newHistoricalRatesServer(historicalRatesXML)
+	defer s.Close()
+
+	finance.FullHistoricalRatesURL = s.URL
+	defer resetHistoricalRatesURL()
+
+	rates, err := finance.FullHistoricalExchangeRates()
+	assert.NoError(t, err, "error")
+
+	date := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	table, err := rates.TableOn(date)
+	assert.NoError(t, err, "error")
+	table.Rates["USD"] = 99
+	table.DecimalRates["USD"] = "99"
+
+	actual, err := rates.TableOn(date)
+	assert.NoError(t, err, "error")
+	assert.Equal(t, 1.0919, actual.Rates["USD"], "rates")
+	assert.Equal(t, "1.0919", actual.DecimalRates["USD"], "decimal-rates")
+
+}
+
+const easterRatesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2024-04-02">
+			<Cube currency="USD" rate="1.0749"/>
+		</Cube>
+		<Cube time="2024-03-28">
+			<Cube currency="USD" rate="1.0811"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestHistoricalExchangeRatesLookupPolicy(t *testing.T) {
+
+	s := newHistoricalRatesServer(easterRatesXML)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithHistoricalRatesURL(s.URL))
+
+	rates, err := client.HistoricalExchangeRates()
+	if !assert.NoError(t, err, "error") {
+		return
+	}
+
+	type test struct {
+		name         string
+		policy       finance.RateLookupPolicy
+		date         time.Time
+		expectedDate string
+		expectedRate float64
+		expectsError bool
+	}
+
+	var tests = []test{
+		{"exact-published", finance.LookupExact, time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC), "2024-03-28", 1.0811, false},
+		{"exact-holiday", finance.LookupExact, time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC), "", 0, true},
+		{"previous-saturday", finance.LookupPreviousBusinessDay, time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC), "2024-03-28", 1.0811, false},
+		{"previous-easter-monday", finance.LookupPreviousBusinessDay, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), "2024-03-28", 1.0811, false},
+		{"next-good-friday", finance.LookupNextBusinessDay, time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC), "2024-04-02", 1.0749, false},
+		{"next-published", finance.LookupNextBusinessDay, time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), "2024-04-02", 1.0749, false},
+		{"previous-before-range", finance.LookupPreviousBusinessDay, time.Date(2024, 3, 24, 0, 0, 0, 0, time.UTC), "", 0, true},
+		{"next-after-range", finance.LookupNextBusinessDay, time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC), "", 0, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			rates.LookupPolicy = tc.policy
+
+			conversion, err := rates.Convert(1, "EUR", "USD", tc.date)
+
+			if tc.expectsError {
+				assert.True(t, errors.Is(err, finance.ErrNoRatesForDate), "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			if assert.NotNil(t, conversion, "conversion") {
+				assert.Equal(t, tc.expectedDate, conversion.Date.Format("2006-01-02"), "date")
+				assert.Equal(t, tc.expectedRate, conversion.Result, "result")
+			}
+
+			publishedOn, err := rates.PublicationDate(tc.date)
+			assert.NoError(t, err, "publication-error")
+			assert.Equal(t, tc.expectedDate, publishedOn.Format("2006-01-02"), "publication-date")
+
+		})
+	}
+
+}
+
+func TestHistoricalExchangeRatesInvalidDate(t *testing.T) {
+
+	s := newHistoricalRatesServer(`<Envelope><Cube><Cube time="yesterday"><Cube currency="USD" rate="1.1"/></Cube></Cube></Envelope>`)
+	defer s.Close()
+
+	finance.HistoricalRatesURL = s.URL
+	defer resetHistoricalRatesURL()
+
+	rates, err := finance.HistoricalExchangeRates()
+
+	assert.Nil(t, rates, "rates")
+	assert.ErrorIs(t, err, finance.ErrInvalidRateDate, "error")
+
+}
+
+func TestHistoricalExchangeRatesInvalidURL(t *testing.T) {
+
+	finance.HistoricalRatesURL = "ht&@-tp://:aa"
+	defer resetHistoricalRatesURL()
+
+	rates, err := finance.HistoricalExchangeRates()
+
+	assert.Nil(t, rates, "rates")
+	assert.Error(t, err, "error")
+
+}
+
+func newHistoricalRatesServer(body string) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(body))
+		}),
+	)
+}
+
+func resetHistoricalRatesURL() {
+	finance.HistoricalRatesURL = finance.DefaultHistoricalRatesURL
+	finance.FullHistoricalRatesURL = finance.DefaultFullHistoricalRatesURL
+}
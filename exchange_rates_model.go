@@ -1,5 +1,11 @@
 package finance
 
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
 // exchangeRate defines the exchange rate
 type exchangeRate struct {
 	Time  string             `xml:"time"`
@@ -19,6 +25,25 @@ type exchangeRateTimedCube struct {
 
 // exchangeRateCurrencyCube defines an exchange rate currency cube
 type exchangeRateCurrencyCube struct {
-	Currency string  `xml:"currency,attr"`
-	Rate     float64 `xml:"rate,attr"`
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"` // The rate exactly as published
+}
+
+// hasRates returns true when the document contains at least one day of rates
+func (r exchangeRate) hasRates() bool {
+	for _, cube := range r.Cubes {
+		if len(cube.TimedCubes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the rate as a float
+func (c exchangeRateCurrencyCube) value() (float64, error) {
+	rate, err := strconv.ParseFloat(c.Rate, 64)
+	if err != nil {
+		return 0, errors.Wrap(ErrInvalidRatesDocument, "invalid rate for "+c.Currency+": "+c.Rate)
+	}
+	return rate, nil
 }
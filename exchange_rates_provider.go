@@ -0,0 +1,343 @@
+package finance
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ECBProviderName is the name reported by the client when it provided the exchange rates
+const ECBProviderName = "ECB"
+
+var (
+	// ErrNoRateProviders is the error returned when a chain doesn't contain any providers
+	ErrNoRateProviders = errors.New("No exchange rate providers configured")
+
+	// ErrInvalidRatesDocument is the error returned when a rates document can't be interpreted
+	ErrInvalidRatesDocument = errors.New("Invalid exchange rates document")
+)
+
+// RateProvider defines a source of exchange rates
+//
+// Client, CachedRates, JSONRateProvider, CSVRateProvider and ChainRateProvider all implement this interface.
+type RateProvider interface {
+	LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error)
+}
+
+// JSONRateProvider reads the exchange rates from a JSON document
+//
+// The document is expected to have the following format:
+//
+//	{"base": "EUR", "date": "2024-01-03", "rates": {"USD": 1.0919, "JPY": 155.52}}
+type JSONRateProvider struct {
+	Name     string // The name reported in the exchange rate table
+	Location string // The URL or file path of the document
+
+	client *Client
+}
+
+// CSVRateProvider reads the exchange rates from a CSV document in the format the ECB uses for its CSV exports
+//
+// The first column contains the date, the other columns contain the rates for the currency in the header. When
+// the document contains multiple rows, the most recent one is used.
+type CSVRateProvider struct {
+	Name     string // The name reported in the exchange rate table
+	Location string // The URL or file path of the document
+
+	client *Client
+}
+
+// ChainRateProvider tries a list of providers in order and returns the rates of the first one which answers
+type ChainRateProvider struct {
+	providers []RateProvider
+}
+
+// ChainError is the error returned when none of the providers in a chain could provide the exchange rates
+type ChainError struct {
+	Errors []error // The errors returned by each of the providers, in order
+}
+
+// NewJSONRateProvider returns a provider which reads a JSON document from a URL or a file
+//
+// The client is used for URLs, when it's nil, a client with the default settings is used.
+func NewJSONRateProvider(location string, client *Client) *JSONRateProvider {
+	if client == nil {
+		client = NewClient()
+	}
+	return &JSONRateProvider{
+		Name:     "JSON",
+		Location: location,
+		client:   client,
+	}
+}
+
+// NewCSVRateProvider returns a provider which reads a CSV document from a URL or a file
+//
+// The client is used for URLs, when it's nil, a client with the default settings is used.
+func NewCSVRateProvider(location string, client *Client) *CSVRateProvider {
+	if client == nil {
+		client = NewClient()
+	}
+	return &CSVRateProvider{
+		Name:     "CSV",
+		Location: location,
+		client:   client,
+	}
+}
+
+// NewChainRateProvider returns a provider which tries the given providers in order
+func NewChainRateProvider(providers ...RateProvider) *ChainRateProvider {
+	return &ChainRateProvider{
+		providers: providers,
+	}
+}
+
+// LatestExchangeRates returns the exchange rates from the JSON document
+func (p *JSONRateProvider) LatestExchangeRates() (*ExchangeRateTable, error) {
+	return p.LatestExchangeRatesContext(context.Background())
+}
+
+// LatestExchangeRatesContext returns the exchange rates from the JSON document using the given context
+func (p *JSONRateProvider) LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error) {
+
+	fetchedAt := time.Now()
+
+	rawData, err := p.client.readLocation(ctx, p.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Base  string                 `json:"base"`
+		Date  string                 `json:"date"`
+		Rates map[string]json.Number `json:"rates"`
+	}
+	if err := json.Unmarshal(rawData, &doc); err != nil {
+		return nil, errors.Wrap(ErrInvalidRatesDocument, err.Error())
+	}
+
+	if len(doc.Rates) == 0 {
+		return nil, errors.Wrap(ErrInvalidRatesDocument, "no rates found")
+	}
+
+	table := &ExchangeRateTable{
+		Base:      strings.ToUpper(doc.Base),
+		Source:    p.Location,
+		Provider:  p.Name,
+		FetchedAt: fetchedAt,
+		Rates:     make(map[string]float64, len(doc.Rates)+1),
+
+		DecimalRates: make(map[string]string, len(doc.Rates)+1),
+	}
+
+	if table.Base == "" {
+		table.Base = BaseCurrency
+	}
+
+	if doc.Date != "" {
+		date, err := time.Parse(rateDateLayout, doc.Date)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidRateDate, doc.Date)
+		}
+		table.Date = date
+	}
+
+	table.Rates[table.Base] = 1
+	table.DecimalRates[table.Base] = "1"
+	for currency, rate := range doc.Rates {
+		value, err := rate.Float64()
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidRatesDocument, "invalid rate for "+currency+": "+rate.String())
+		}
+		currency = strings.ToUpper(currency)
+		table.Rates[currency] = value
+		table.DecimalRates[currency] = rate.String()
+	}
+
+	return table, nil
+
+}
+
+// LatestExchangeRates returns the exchange rates from the CSV document
+func (p *CSVRateProvider) LatestExchangeRates() (*ExchangeRateTable, error) {
+	return p.LatestExchangeRatesContext(context.Background())
+}
+
+// LatestExchangeRatesContext returns the exchange rates from the CSV document using the given context
+func (p *CSVRateProvider) LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error) {
+
+	fetchedAt := time.Now()
+
+	rawData, err := p.client.readLocation(ctx, p.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(rawData))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidRatesDocument, "missing header")
+	}
+
+	if len(header) < 2 || !strings.EqualFold(strings.TrimSpace(header[0]), "date") {
+		return nil, errors.Wrap(ErrInvalidRatesDocument, "first column should be the date")
+	}
+
+	var latest *ExchangeRateTable
+
+	for {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidRatesDocument, err.Error())
+		}
+
+		date, err := parseCSVRateDate(record[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if latest != nil && !date.After(latest.Date) {
+			continue
+		}
+
+		table := &ExchangeRateTable{
+			Date:      date,
+			Base:      BaseCurrency,
+			Source:    p.Location,
+			Provider:  p.Name,
+			FetchedAt: fetchedAt,
+			Rates:     map[string]float64{BaseCurrency: 1},
+
+			DecimalRates: map[string]string{BaseCurrency: "1"},
+		}
+
+		for i := 1; i < len(record) && i < len(header); i++ {
+
+			currency := strings.ToUpper(strings.TrimSpace(header[i]))
+			value := strings.TrimSpace(record[i])
+			if currency == "" || value == "" || value == "N/A" {
+				continue
+			}
+
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidRatesDocument, "invalid rate for "+currency+": "+value)
+			}
+			table.Rates[currency] = rate
+			table.DecimalRates[currency] = value
+
+		}
+
+		latest = table
+
+	}
+
+	if latest == nil {
+		return nil, errors.Wrap(ErrInvalidRatesDocument, "no rates found")
+	}
+
+	return latest, nil
+
+}
+
+// LatestExchangeRates returns the exchange rates from the first provider which answers
+func (p *ChainRateProvider) LatestExchangeRates() (*ExchangeRateTable, error) {
+	return p.LatestExchangeRatesContext(context.Background())
+}
+
+// LatestExchangeRatesContext returns the exchange rates from the first provider which answers using the given context
+//
+// The Provider field of the returned table indicates which provider answered.
+func (p *ChainRateProvider) LatestExchangeRatesContext(ctx context.Context) (*ExchangeRateTable, error) {
+
+	if len(p.providers) == 0 {
+		return nil, ErrNoRateProviders
+	}
+
+	chainErr := &ChainError{}
+
+	for _, provider := range p.providers {
+
+		table, err := provider.LatestExchangeRatesContext(ctx)
+		if err == nil {
+			return table, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		chainErr.Errors = append(chainErr.Errors, err)
+
+	}
+
+	return nil, chainErr
+
+}
+
+// Error returns the errors of all providers
+func (e *ChainError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "All exchange rate providers failed: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of all providers, so errors.Is and errors.As look at each of them
+func (e *ChainError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is returns true when the error of one of the providers matches target
+//
+// This does the same as Unwrap for versions of Go whose errors.Is doesn't look at multiple errors.
+func (e *ChainError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCSVRateDate parses the dates used in the ECB CSV exports
+func parseCSVRateDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{rateDateLayout, "02 January 2006", "2 January 2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.Wrap(ErrInvalidRateDate, value)
+}
+
+// readLocation reads a document from a URL or a file
+func (c *Client) readLocation(ctx context.Context, location string) ([]byte, error) {
+
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		rawData, err := c.doRequest(ctx, c.ratesTimeout, "GET", location, "", nil, retryOnServerError)
+		if unreachable, ok := err.(*unreachableError); ok {
+			return nil, unreachable.err
+		}
+		return rawData, err
+	}
+
+	return ioutil.ReadFile(strings.TrimPrefix(location, "file://"))
+
+}
@@ -0,0 +1,220 @@
+package finance_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+var (
+	_ finance.RateProvider = &finance.Client{}
+	_ finance.RateProvider = &finance.CachedRates{}
+	_ finance.RateProvider = &finance.JSONRateProvider{}
+	_ finance.RateProvider = &finance.CSVRateProvider{}
+	_ finance.RateProvider = &finance.ChainRateProvider{}
+)
+
+const jsonRates = `{"base": "EUR", "date": "2024-01-03", "rates": {"USD": 1.0919, "jpy": 155.52}}`
+
+const csvRates = `Date, USD, JPY, BGN, CYP, 
+03 January 2024, 1.0919, 155.52, 1.9558, N/A, 
+02 January 2024, 1.0956, 155.07, 1.9558, N/A, 
+`
+
+func TestJSONRateProvider(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(jsonRates))
+		}),
+	)
+	defer s.Close()
+
+	provider := finance.NewJSONRateProvider(s.URL, nil)
+
+	table, err := provider.LatestExchangeRates()
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, table, "table") {
+		assert.Equal(t, "JSON", table.Provider, "provider")
+		assert.Equal(t, s.URL, table.Source, "source")
+		assert.Equal(t, "EUR", table.Base, "base")
+		assert.Equal(t, "2024-01-03", table.Date.Format("2006-01-02"), "date")
+		assert.Equal(t, 1.0919, table.Rates["USD"], "usd")
+		assert.Equal(t, 155.52, table.Rates["JPY"], "jpy")
+		assert.Equal(t, 1.0, table.Rates["EUR"], "eur")
+	}
+
+}
+
+func TestJSONRateProviderInvalid(t *testing.T) {
+
+	type test struct {
+		name    string
+		content string
+	}
+
+	var tests = []test{
+		{"not-json", "hello"},
+		{"no-rates", `{"base": "EUR", "date": "2024-01-03"}`},
+		{"invalid-date", `{"date": "yesterday", "rates": {"USD": 1.1}}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			path := writeTempFile(t, "rates.json", tc.content)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			table, err := finance.NewJSONRateProvider(path, nil).LatestExchangeRates()
+
+			assert.Nil(t, table, "table")
+			assert.Error(t, err, "error")
+
+		})
+	}
+
+}
+
+func TestCSVRateProvider(t *testing.T) {
+
+	path := writeTempFile(t, "eurofxref.csv", csvRates)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	provider := finance.NewCSVRateProvider("file://"+path, nil)
+	provider.Name = "ECB mirror"
+
+	table, err := provider.LatestExchangeRates()
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, table, "table") {
+		assert.Equal(t, "ECB mirror", table.Provider, "provider")
+		assert.Equal(t, "2024-01-03", table.Date.Format("2006-01-02"), "date")
+		assert.Equal(t, 1.0919, table.Rates["USD"], "usd")
+		assert.Equal(t, 1.9558, table.Rates["BGN"], "bgn")
+		assert.NotContains(t, table.Rates, "CYP", "cyp")
+		assert.Len(t, table.Rates, 4, "rates")
+	}
+
+}
+
+func TestCSVRateProviderInvalid(t *testing.T) {
+
+	type test struct {
+		name    string
+		content string
+	}
+
+	var tests = []test{
+		{"empty", ""},
+		{"no-date-column", "USD, JPY\n1.1, 155\n"},
+		{"no-rows", "Date, USD\n"},
+		{"invalid-date", "Date, USD\nyesterday, 1.1\n"},
+		{"invalid-rate", "Date, USD\n2024-01-03, abc\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			path := writeTempFile(t, "rates.csv", tc.content)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			table, err := finance.NewCSVRateProvider(path, nil).LatestExchangeRates()
+
+			assert.Nil(t, table, "table")
+			assert.Error(t, err, "error")
+
+		})
+	}
+
+}
+
+func TestChainRateProvider(t *testing.T) {
+
+	path := writeTempFile(t, "eurofxref.csv", csvRates)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	chain := finance.NewChainRateProvider(
+		finance.NewClient(finance.WithRatesURL("ht&@-tp://:aa")),
+		finance.NewJSONRateProvider(filepath.Join(filepath.Dir(path), "missing.json"), nil),
+		finance.NewCSVRateProvider(path, nil),
+	)
+
+	table, err := chain.LatestExchangeRates()
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, table, "table") {
+		assert.Equal(t, "CSV", table.Provider, "provider")
+	}
+
+	conversion, err := table.Convert(2, "EUR", "USD")
+	assert.NoError(t, err, "error")
+	assert.Equal(t, "CSV", conversion.Provider, "conversion-provider")
+
+}
+
+func TestChainRateProviderAllFail(t *testing.T) {
+
+	chain := finance.NewChainRateProvider(
+		finance.NewClient(finance.WithRatesURL("ht&@-tp://:aa")),
+		finance.NewJSONRateProvider("/does/not/exist.json", nil),
+	)
+
+	table, err := chain.LatestExchangeRates()
+
+	assert.Nil(t, table, "table")
+	if chainErr, ok := err.(*finance.ChainError); assert.True(t, ok, "chain-error") {
+		assert.Len(t, chainErr.Errors, 2, "errors")
+	}
+	assert.True(t, errors.Is(err, os.ErrNotExist), "is-not-exist")
+	assert.False(t, errors.Is(err, finance.ErrNoRateProviders), "is-other")
+
+	var pathErr *os.PathError
+	if assert.True(t, errors.As(err, &pathErr), "as-path-error") {
+		assert.Equal(t, "/does/not/exist.json", pathErr.Path, "path")
+	}
+
+	table, err = finance.NewChainRateProvider().LatestExchangeRates()
+
+	assert.Nil(t, table, "table")
+	assert.Equal(t, finance.ErrNoRateProviders, err, "error")
+
+}
+
+func TestCachedRatesWithProvider(t *testing.T) {
+
+	path := writeTempFile(t, "rates.json", jsonRates)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	cache := finance.NewCachedRates(finance.NewJSONRateProvider(path, nil))
+
+	actual, err := cache.ConvertRate(2, "EUR", "USD")
+
+	assert.NoError(t, err, "error")
+	assert.Equal(t, 2.1838, actual, "actual")
+
+}
+
+func writeTempFile(t *testing.T, name string, content string) string {
+
+	dir, err := ioutil.TempDir("", "go-finance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+
+}
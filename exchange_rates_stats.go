@@ -0,0 +1,288 @@
+package finance
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrEmptySeries is the error returned when a statistic is requested for a series without values
+	ErrEmptySeries = errors.New("The rate series is empty")
+
+	// ErrInvalidWindow is the error returned when a rolling window contains less than 2 returns
+	ErrInvalidWindow = errors.New("The window should contain at least 2 returns")
+)
+
+// RatePoint is a single value in a rate series
+type RatePoint struct {
+	Date  time.Time // The publication date, or the first day of the period for averages
+	Value float64   // The value
+	Count int       // The number of published rates the value is based on
+}
+
+// RateSeries contains the values of an exchange rate over time, sorted by date
+type RateSeries struct {
+	Currency string      // The currency of the rates
+	Name     string      // Describes what the values are, e.g. "rate" or "monthly average"
+	Points   []RatePoint // The values, sorted ascending by date
+}
+
+// RateSummary contains the statistics of a rate series over its period
+type RateSummary struct {
+	Currency string    // The currency of the rates
+	From     time.Time // The date of the first value
+	To       time.Time // The date of the last value
+	Open     float64   // The first value
+	Close    float64   // The last value
+	Min      float64   // The lowest value
+	MinDate  time.Time // The date of the lowest value
+	Max      float64   // The highest value
+	MaxDate  time.Time // The date of the highest value
+	Average  float64   // The average of all values
+	Change   float64   // The change from the first to the last value, in percent
+	Count    int       // The number of values
+}
+
+// Series returns the published rates of a currency between from and to, both inclusive
+//
+// A zero from or to leaves that side of the period open.
+func (h *HistoricalRates) Series(currency string, from time.Time, to time.Time) (*RateSeries, error) {
+
+	code, err := NormalizeCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &RateSeries{Currency: code, Name: "rate"}
+	quoted := false
+
+	for _, date := range h.dates {
+
+		rate, ok := h.rates[date.Format(rateDateLayout)][code]
+		if !ok {
+			continue
+		}
+		quoted = true
+
+		if !from.IsZero() && date.Before(truncateToDay(from)) {
+			continue
+		}
+		if !to.IsZero() && date.After(truncateToDay(to)) {
+			continue
+		}
+
+		series.Points = append(series.Points, RatePoint{Date: date, Value: rate, Count: 1})
+
+	}
+
+	if !quoted {
+		return nil, errors.Wrap(ErrCurrencyNotQuoted, code)
+	}
+
+	return series, nil
+
+}
+
+// MonthlyAverages returns the average value of each calendar month
+func (s *RateSeries) MonthlyAverages() *RateSeries {
+	return s.averages("monthly average", func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+// YearlyAverages returns the average value of each calendar year
+func (s *RateSeries) YearlyAverages() *RateSeries {
+	return s.averages("yearly average", func(t time.Time) time.Time {
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	})
+}
+
+// Summary returns the open, close, minimum, maximum, average and percentage change of the series
+func (s *RateSeries) Summary() (*RateSummary, error) {
+
+	if len(s.Points) == 0 {
+		return nil, errors.Wrap(ErrEmptySeries, s.Currency)
+	}
+
+	first := s.Points[0]
+	last := s.Points[len(s.Points)-1]
+
+	summary := &RateSummary{
+		Currency: s.Currency,
+		From:     first.Date,
+		To:       last.Date,
+		Open:     first.Value,
+		Close:    last.Value,
+		Min:      first.Value,
+		MinDate:  first.Date,
+		Max:      first.Value,
+		MaxDate:  first.Date,
+		Count:    len(s.Points),
+	}
+
+	total := 0.0
+	for _, point := range s.Points {
+		total += point.Value
+		if point.Value < summary.Min {
+			summary.Min = point.Value
+			summary.MinDate = point.Date
+		}
+		if point.Value > summary.Max {
+			summary.Max = point.Value
+			summary.MaxDate = point.Date
+		}
+	}
+
+	summary.Average = total / float64(len(s.Points))
+	if first.Value != 0 {
+		summary.Change = (last.Value - first.Value) / first.Value * 100
+	}
+
+	return summary, nil
+
+}
+
+// PercentageChanges returns the change of each value compared to the previous one, in percent
+func (s *RateSeries) PercentageChanges() *RateSeries {
+
+	result := &RateSeries{Currency: s.Currency, Name: "change %"}
+
+	for i := 1; i < len(s.Points); i++ {
+		previous := s.Points[i-1].Value
+		if previous == 0 {
+			continue
+		}
+		result.Points = append(result.Points, RatePoint{
+			Date:  s.Points[i].Date,
+			Value: (s.Points[i].Value - previous) / previous * 100,
+			Count: 2,
+		})
+	}
+
+	return result
+
+}
+
+// RollingVolatility returns the sample standard deviation of the daily logarithmic returns over a rolling window
+//
+// Each value is based on the last window returns up to its date, so it uses window+1 publications. The volatility
+// is not annualized.
+func (s *RateSeries) RollingVolatility(window int) (*RateSeries, error) {
+
+	if window < 2 {
+		return nil, ErrInvalidWindow
+	}
+
+	result := &RateSeries{Currency: s.Currency, Name: "volatility " + strconv.Itoa(window)}
+
+	returns := make([]float64, len(s.Points))
+	for i := 1; i < len(s.Points); i++ {
+		if s.Points[i-1].Value > 0 && s.Points[i].Value > 0 {
+			returns[i] = math.Log(s.Points[i].Value / s.Points[i-1].Value)
+		}
+	}
+
+	for end := window; end < len(s.Points); end++ {
+		result.Points = append(result.Points, RatePoint{
+			Date:  s.Points[end].Date,
+			Value: stdDev(returns[end-window+1 : end+1]),
+			Count: window + 1,
+		})
+	}
+
+	return result, nil
+
+}
+
+// WriteCSV writes the series as CSV with a date, a value and a count column
+//
+// The values are rounded to 10 decimals to hide floating-point noise.
+func (s *RateSeries) WriteCSV(w io.Writer) error {
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"date", s.Currency + " " + s.Name, "count"}); err != nil {
+		return err
+	}
+
+	for _, point := range s.Points {
+		record := []string{
+			point.Date.Format(rateDateLayout),
+			formatSeriesValue(point.Value),
+			strconv.Itoa(point.Count),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+
+}
+
+// averages groups the values per period and returns the average of each period
+func (s *RateSeries) averages(name string, period func(time.Time) time.Time) *RateSeries {
+
+	result := &RateSeries{Currency: s.Currency, Name: name}
+
+	for _, point := range s.Points {
+		start := period(point.Date)
+		if n := len(result.Points); n == 0 || !result.Points[n-1].Date.Equal(start) {
+			result.Points = append(result.Points, RatePoint{Date: start})
+		}
+		last := &result.Points[len(result.Points)-1]
+		last.Value += point.Value
+		last.Count++
+	}
+
+	for i := range result.Points {
+		result.Points[i].Value /= float64(result.Points[i].Count)
+	}
+
+	return result
+
+}
+
+// formatSeriesValue formats a value with at most 10 decimals and without trailing zeros
+func formatSeriesValue(value float64) string {
+	formatted := strconv.FormatFloat(value, 'f', 10, 64)
+	formatted = strings.TrimSuffix(strings.TrimRight(formatted, "0"), ".")
+	if formatted == "" || formatted == "-" {
+		return "0"
+	}
+	return formatted
+}
+
+// stdDev returns the sample standard deviation of the values
+func stdDev(values []float64) float64 {
+
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(values)-1))
+
+}
+
+// truncateToDay returns the calendar date of t at midnight UTC, the way the ECB publication dates are stored
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
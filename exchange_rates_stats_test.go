@@ -0,0 +1,147 @@
+package finance_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+const seriesRatesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2024-02-01"><Cube currency="USD" rate="1.08"/></Cube>
+		<Cube time="2024-01-03"><Cube currency="USD" rate="1.09"/></Cube>
+		<Cube time="2024-01-02"><Cube currency="USD" rate="1.10"/></Cube>
+		<Cube time="2023-12-29"><Cube currency="USD" rate="1.12"/></Cube>
+		<Cube time="2023-12-28"><Cube currency="USD" rate="1.10"/><Cube currency="JPY" rate="156.5"/></Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func loadSeriesRates(t *testing.T) *finance.HistoricalRates {
+
+	s := newHistoricalRatesServer(seriesRatesXML)
+	defer s.Close()
+
+	rates, err := finance.NewClient(finance.WithHistoricalRatesURL(s.URL)).HistoricalExchangeRates()
+	if !assert.NoError(t, err, "load") {
+		t.FailNow()
+	}
+
+	return rates
+
+}
+
+func TestRateSeries(t *testing.T) {
+
+	rates := loadSeriesRates(t)
+
+	series, err := rates.Series("usd", time.Time{}, time.Time{})
+	if !assert.NoError(t, err, "error") {
+		return
+	}
+	assert.Equal(t, "USD", series.Currency, "currency")
+	assert.Len(t, series.Points, 5, "points")
+
+	series, err = rates.Series("USD", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err, "range-error")
+	assert.Len(t, series.Points, 2, "range-points")
+
+	_, err = rates.Series("ABC", time.Time{}, time.Time{})
+	assert.True(t, errors.Is(err, finance.ErrUnknownCurrency), "unknown")
+
+	_, err = rates.Series("CHF", time.Time{}, time.Time{})
+	assert.True(t, errors.Is(err, finance.ErrCurrencyNotQuoted), "not-quoted")
+
+	series, err = rates.Series("JPY", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	assert.NoError(t, err, "empty-error")
+	_, err = series.Summary()
+	assert.True(t, errors.Is(err, finance.ErrEmptySeries), "empty-summary")
+
+}
+
+func TestRateSeriesAverages(t *testing.T) {
+
+	series, _ := loadSeriesRates(t).Series("USD", time.Time{}, time.Time{})
+
+	monthly := series.MonthlyAverages()
+	if assert.Len(t, monthly.Points, 3, "monthly") {
+		assert.Equal(t, "2023-12-01", monthly.Points[0].Date.Format("2006-01-02"), "monthly-date")
+		assert.InDelta(t, 1.11, monthly.Points[0].Value, 1e-12, "monthly-december")
+		assert.Equal(t, 2, monthly.Points[0].Count, "monthly-count")
+		assert.InDelta(t, 1.095, monthly.Points[1].Value, 1e-12, "monthly-january")
+		assert.InDelta(t, 1.08, monthly.Points[2].Value, 1e-12, "monthly-february")
+	}
+
+	yearly := series.YearlyAverages()
+	if assert.Len(t, yearly.Points, 2, "yearly") {
+		assert.Equal(t, "2024-01-01", yearly.Points[1].Date.Format("2006-01-02"), "yearly-date")
+		assert.InDelta(t, (1.10+1.09+1.08)/3, yearly.Points[1].Value, 1e-12, "yearly-2024")
+		assert.Equal(t, 3, yearly.Points[1].Count, "yearly-count")
+	}
+
+}
+
+func TestRateSeriesSummary(t *testing.T) {
+
+	series, _ := loadSeriesRates(t).Series("USD", time.Time{}, time.Time{})
+
+	summary, err := series.Summary()
+	if !assert.NoError(t, err, "error") {
+		return
+	}
+
+	assert.Equal(t, 1.10, summary.Open, "open")
+	assert.Equal(t, 1.08, summary.Close, "close")
+	assert.Equal(t, 1.08, summary.Min, "min")
+	assert.Equal(t, "2024-02-01", summary.MinDate.Format("2006-01-02"), "min-date")
+	assert.Equal(t, 1.12, summary.Max, "max")
+	assert.Equal(t, "2023-12-29", summary.MaxDate.Format("2006-01-02"), "max-date")
+	assert.InDelta(t, 1.098, summary.Average, 1e-12, "average")
+	assert.InDelta(t, (1.08-1.10)/1.10*100, summary.Change, 1e-12, "change")
+	assert.Equal(t, 5, summary.Count, "count")
+
+	changes := series.PercentageChanges()
+	if assert.Len(t, changes.Points, 4, "changes") {
+		assert.InDelta(t, (1.12-1.10)/1.10*100, changes.Points[0].Value, 1e-12, "first-change")
+	}
+
+}
+
+func TestRateSeriesRollingVolatility(t *testing.T) {
+
+	series, _ := loadSeriesRates(t).Series("USD", time.Time{}, time.Time{})
+
+	_, err := series.RollingVolatility(1)
+	assert.Equal(t, finance.ErrInvalidWindow, err, "invalid-window")
+
+	volatility, err := series.RollingVolatility(2)
+	if !assert.NoError(t, err, "error") || !assert.Len(t, volatility.Points, 3, "points") {
+		return
+	}
+
+	r1 := math.Log(1.12 / 1.10)
+	r2 := math.Log(1.10 / 1.12)
+	mean := (r1 + r2) / 2
+	expected := math.Sqrt((r1-mean)*(r1-mean) + (r2-mean)*(r2-mean))
+
+	assert.Equal(t, "2024-01-02", volatility.Points[0].Date.Format("2006-01-02"), "date")
+	assert.InDelta(t, expected, volatility.Points[0].Value, 1e-12, "volatility")
+	assert.Equal(t, 3, volatility.Points[0].Count, "count")
+
+}
+
+func TestRateSeriesWriteCSV(t *testing.T) {
+
+	series, _ := loadSeriesRates(t).Series("USD", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+
+	var buf bytes.Buffer
+	assert.NoError(t, series.MonthlyAverages().WriteCSV(&buf), "error")
+	assert.Equal(t, "date,USD monthly average,count\n2024-01-01,1.095,2\n2024-02-01,1.08,1\n", buf.String(), "csv")
+
+}
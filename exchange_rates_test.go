@@ -1,6 +1,8 @@
 package finance_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,10 +11,17 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
 )
 
 func TestExchangeRatesValid(t *testing.T) {
 
+	s := financetest.NewECBServer()
+	defer s.Close()
+
+	finance.RatesURL = s.DailyURL()
+	defer resetRatesURL()
+
 	rates, err := finance.ExchangeRates()
 
 	assert.NoErrorf(t, err, "err should be nil, is: %v", err)
@@ -114,6 +123,12 @@ func TestConvertRate(t *testing.T) {
 		{"valid-aud-usd", 2, "AUD", "USD", false},
 	}
 
+	s := financetest.NewECBServer()
+	defer s.Close()
+
+	finance.RatesURL = s.DailyURL()
+	defer resetRatesURL()
+
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 
@@ -147,6 +162,167 @@ func TestConvertRateInvalidURL(t *testing.T) {
 
 }
 
+const dailyRatesXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2024-01-03">
+			<Cube currency="USD" rate="1.0919"/>
+			<Cube currency="JPY" rate="155.52"/>
+			<Cube currency="GBP" rate="0.86518"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestLatestExchangeRates(t *testing.T) {
+
+	s := newDailyRatesServer()
+	defer s.Close()
+
+	finance.RatesURL = s.URL
+	defer resetRatesURL()
+
+	table, err := finance.LatestExchangeRates()
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, table, "table") {
+		assert.Equal(t, "2024-01-03", table.Date.Format("2006-01-02"), "date")
+		assert.Equal(t, "EUR", table.Base, "base")
+		assert.Equal(t, s.URL, table.Source, "source")
+		assert.False(t, table.FetchedAt.IsZero(), "fetched-at")
+		assert.Equal(t, 1.0919, table.Rates["USD"], "usd")
+		assert.Equal(t, 1.0, table.Rates["EUR"], "eur")
+	}
+
+}
+
+func TestConvert(t *testing.T) {
+
+	s := newDailyRatesServer()
+	defer s.Close()
+
+	finance.RatesURL = s.URL
+	defer resetRatesURL()
+
+	conversion, err := finance.Convert(2, "EUR", "USD")
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, conversion, "conversion") {
+		assert.Equal(t, 2.1838, conversion.Result, "result")
+		assert.Equal(t, 1.0919, conversion.Rate, "rate")
+		assert.Equal(t, "2024-01-03", conversion.Date.Format("2006-01-02"), "date")
+		assert.Equal(t, s.URL, conversion.Source, "source")
+	}
+
+	conversion, err = finance.Convert(2, "EUR", "XXX")
+
+	assert.Nil(t, conversion, "conversion")
+	assert.Error(t, err, "error")
+
+}
+
+func TestConvertRateCurrencyErrors(t *testing.T) {
+
+	s := newDailyRatesServer()
+	defer s.Close()
+
+	finance.RatesURL = s.URL
+	defer resetRatesURL()
+
+	type test struct {
+		name          string
+		from          string
+		to            string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"lowercase", "eur", " usd ", nil},
+		{"unknown-from", "ABC", "USD", finance.ErrUnknownCurrency},
+		{"unknown-to", "EUR", "", finance.ErrUnknownCurrency},
+		{"not-quoted-from", "XAU", "USD", finance.ErrCurrencyNotQuoted},
+		{"not-quoted-to", "EUR", "chf", finance.ErrCurrencyNotQuoted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			actual, err := finance.ConvertRate(2, tc.from, tc.to)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err, "error")
+				assert.Equal(t, 2.1838, actual, "actual")
+				return
+			}
+
+			assert.Zero(t, actual, "actual")
+			assert.True(t, errors.Is(err, tc.expectedError), "error")
+
+		})
+	}
+
+}
+
+func TestExchangeRatesEmptyDocument(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01"><Cube></Cube></gesmes:Envelope>`))
+		}),
+	)
+	defer s.Close()
+
+	finance.RatesURL = s.URL
+	defer resetRatesURL()
+
+	table, err := finance.LatestExchangeRates()
+
+	assert.Nil(t, table, "table")
+	assert.True(t, errors.Is(err, finance.ErrInvalidRatesDocument), "error")
+
+}
+
+func TestExchangeRatesContextCancelled(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	finance.RatesURL = s.URL
+	defer resetRatesURL()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	rates, err := finance.ExchangeRatesContext(ctx)
+
+	assert.Empty(t, rates, "rates")
+	assert.Equal(t, context.DeadlineExceeded, err, "error")
+
+	conversion, err := finance.ConvertContext(ctx, 1, "EUR", "USD")
+
+	assert.Nil(t, conversion, "conversion")
+	assert.Equal(t, context.DeadlineExceeded, err, "error")
+
+}
+
+func newDailyRatesServer() *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+}
+
 func resetRatesURL() {
 	finance.RatesURL = finance.DefaultRatesURL
 }
@@ -0,0 +1,195 @@
+package financetest
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// The paths on which the fake ECB server publishes its documents
+const (
+	ECBDailyPath          = "/eurofxref-daily.xml"    // The rates of the most recent day
+	ECBHistoricalPath     = "/eurofxref-hist-90d.xml" // The rates of the last 90 days
+	ECBFullHistoricalPath = "/eurofxref-hist.xml"     // All rates
+)
+
+// ecbDateLayout is the layout of the time attribute used by the ECB
+const ecbDateLayout = "2006-01-02"
+
+// DailyRates are the reference rates the ECB published on a single day
+type DailyRates struct {
+	Date  string            // The publication date, e.g. 2024-01-03
+	Rates map[string]string // The rates against the euro exactly as published, keyed by currency
+}
+
+// ECBServer is a fake ECB server which publishes the daily and historical reference rates
+type ECBServer struct {
+	server
+
+	daysMu sync.RWMutex
+	days   map[string]DailyRates // The fixtures, keyed by date
+}
+
+// DefaultDailyRates are the reference rates of 3 January 2024 for a few currencies
+var DefaultDailyRates = DailyRates{
+	Date: "2024-01-03",
+	Rates: map[string]string{
+		"USD": "1.0919",
+		"JPY": "155.52",
+		"GBP": "0.86518",
+		"CHF": "0.9305",
+		"AUD": "1.6173",
+	},
+}
+
+// NewECBServer starts a fake ECB server which publishes the given rates
+//
+// When no rates are given, DefaultDailyRates are published. The server should be closed when it's no longer needed.
+func NewECBServer(days ...DailyRates) *ECBServer {
+	s := &ECBServer{
+		days: make(map[string]DailyRates),
+	}
+	if len(days) == 0 {
+		days = []DailyRates{DefaultDailyRates}
+	}
+	for _, day := range days {
+		s.Add(day)
+	}
+	s.start(s.handle)
+	return s
+}
+
+// Add adds or replaces the rates of a day in the fixtures
+func (s *ECBServer) Add(day DailyRates) {
+	s.daysMu.Lock()
+	defer s.daysMu.Unlock()
+	s.days[day.Date] = day
+}
+
+// DailyURL returns the URL of the document with the rates of the most recent day
+func (s *ECBServer) DailyURL() string {
+	return s.URL + ECBDailyPath
+}
+
+// HistoricalURL returns the URL of the document with the rates of the last 90 days
+func (s *ECBServer) HistoricalURL() string {
+	return s.URL + ECBHistoricalPath
+}
+
+// FullHistoricalURL returns the URL of the document with all rates
+func (s *ECBServer) FullHistoricalURL() string {
+	return s.URL + ECBFullHistoricalPath
+}
+
+// ClientOptions returns the options which make a finance client use the fake ECB server
+func (s *ECBServer) ClientOptions() []finance.ClientOption {
+	return []finance.ClientOption{
+		finance.WithRatesURL(s.DailyURL()),
+		finance.WithHistoricalRatesURL(s.HistoricalURL()),
+		finance.WithFullHistoricalRatesURL(s.FullHistoricalURL()),
+	}
+}
+
+// handle answers a request for one of the documents
+func (s *ECBServer) handle(w http.ResponseWriter, r *http.Request) {
+
+	days := s.sortedDays()
+
+	switch r.URL.Path {
+	case ECBDailyPath:
+		if len(days) > 0 {
+			days = days[0:1]
+		}
+	case ECBHistoricalPath:
+		days = lastDays(days, 90)
+	case ECBFullHistoricalPath:
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.Write(ecbDocument(days))
+
+}
+
+// sortedDays returns the fixtures, most recent first, the way the ECB orders them
+func (s *ECBServer) sortedDays() []DailyRates {
+
+	s.daysMu.RLock()
+	defer s.daysMu.RUnlock()
+
+	days := make([]DailyRates, 0, len(s.days))
+	for _, day := range s.days {
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date > days[j].Date
+	})
+
+	return days
+
+}
+
+// lastDays returns the days published within the given number of days of the most recent one
+func lastDays(days []DailyRates, count int) []DailyRates {
+
+	if len(days) == 0 {
+		return days
+	}
+
+	latest, err := time.Parse(ecbDateLayout, days[0].Date)
+	if err != nil {
+		return days
+	}
+	since := latest.AddDate(0, 0, -count).Format(ecbDateLayout)
+
+	for i, day := range days {
+		if day.Date <= since {
+			return days[0:i]
+		}
+	}
+
+	return days
+
+}
+
+// ecbDocument builds a document in the format of the ECB reference rates feeds
+func ecbDocument(days []DailyRates) []byte {
+
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	body.WriteString(`<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">` + "\n")
+	body.WriteString("\t<gesmes:subject>Reference rates</gesmes:subject>\n")
+	body.WriteString("\t<gesmes:Sender>\n\t\t<gesmes:name>European Central Bank</gesmes:name>\n\t</gesmes:Sender>\n")
+	body.WriteString("\t<Cube>\n")
+
+	for _, day := range days {
+
+		body.WriteString("\t\t<Cube time=\"" + day.Date + "\">\n")
+
+		currencies := make([]string, 0, len(day.Rates))
+		for currency := range day.Rates {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+
+		for _, currency := range currencies {
+			body.WriteString("\t\t\t<Cube currency=\"" + strings.ToUpper(currency) + "\" rate=\"" + day.Rates[currency] + "\"/>\n")
+		}
+
+		body.WriteString("\t\t</Cube>\n")
+
+	}
+
+	body.WriteString("\t</Cube>\n</gesmes:Envelope>\n")
+
+	return body.Bytes()
+
+}
@@ -0,0 +1,55 @@
+package financetest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
+)
+
+func TestECBServer(t *testing.T) {
+
+	s := financetest.NewECBServer(
+		financetest.DailyRates{Date: "2024-01-03", Rates: map[string]string{"USD": "1.0919", "JPY": "155.52"}},
+		financetest.DailyRates{Date: "2024-01-02", Rates: map[string]string{"USD": "1.0956", "JPY": "155.11"}},
+		financetest.DailyRates{Date: "2023-09-01", Rates: map[string]string{"USD": "1.0844"}},
+	)
+	defer s.Close()
+
+	client := finance.NewClient(s.ClientOptions()...)
+
+	table, err := client.LatestExchangeRates()
+	assert.NoError(t, err, "daily-error")
+	if assert.NotNil(t, table, "daily") {
+		assert.Equal(t, "2024-01-03", table.Date.Format("2006-01-02"), "daily-date")
+		assert.Equal(t, 155.52, table.Rates["JPY"], "daily-jpy")
+		assert.Equal(t, "1.0919", table.DecimalRates["USD"], "daily-decimal-usd")
+	}
+
+	history, err := client.HistoricalExchangeRates()
+	assert.NoError(t, err, "historical-error")
+	if assert.NotNil(t, history, "historical") {
+		assert.Len(t, history.Dates(), 2, "historical-dates")
+		rate, err := history.Rate("USD", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err, "historical-rate-error")
+		assert.Equal(t, 1.0956, rate, "historical-rate")
+	}
+
+	history, err = client.FullHistoricalExchangeRates()
+	assert.NoError(t, err, "full-error")
+	if assert.NotNil(t, history, "full") {
+		assert.Len(t, history.Dates(), 3, "full-dates")
+	}
+
+	s.Add(financetest.DailyRates{Date: "2024-01-04", Rates: map[string]string{"USD": "1.0953"}})
+
+	table, err = client.LatestExchangeRates()
+	assert.NoError(t, err, "added-error")
+	if assert.NotNil(t, table, "added") {
+		assert.Equal(t, "2024-01-04", table.Date.Format("2006-01-02"), "added-date")
+	}
+
+}
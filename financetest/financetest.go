@@ -0,0 +1,169 @@
+// Package financetest provides fake VIES, ECB and IBANBIC servers for testing code which uses the finance package
+//
+// The servers run locally using httptest, are configured with fixtures and can inject failures such as latency,
+// server errors and malformed responses. Pass the options returned by ClientOptions to finance.NewClient to use them.
+package financetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Failure describes how a fake server misbehaves when answering a request
+//
+// The zero value answers normally. When both StatusCode and Body are empty, the normal response is sent after
+// the latency.
+type Failure struct {
+	Latency    time.Duration // How long to wait before answering
+	StatusCode int           // The status code to answer with instead of the normal one
+	Body       string        // The body to answer with instead of the normal one
+	Disconnect bool          // Closes the connection without answering
+}
+
+// Latency returns a failure which delays the response
+func Latency(latency time.Duration) Failure {
+	return Failure{Latency: latency}
+}
+
+// ServerError returns a failure which answers with the given status code and an HTML error page
+func ServerError(statusCode int) Failure {
+	return Failure{
+		StatusCode: statusCode,
+		Body:       "<html><body><h1>" + http.StatusText(statusCode) + "</h1></body></html>",
+	}
+}
+
+// MalformedXML returns a failure which answers with a truncated XML document
+func MalformedXML() Failure {
+	return Failure{
+		Body: `<?xml version="1.0" encoding="UTF-8"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>`,
+	}
+}
+
+// Disconnect returns a failure which closes the connection without answering
+func Disconnect() Failure {
+	return Failure{Disconnect: true}
+}
+
+// server contains the request counting and failure injection shared by the fake servers
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int       // The number of requests received
+	queued   []Failure // The failures to apply to the next requests, in order
+	always   *Failure  // The failure to apply to every request when nothing is queued
+}
+
+// start starts the server with the given handler
+func (s *server) start(handler http.HandlerFunc) {
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serve(w, r, handler)
+	}))
+}
+
+// Fail makes the next requests fail, one failure per request
+//
+// Once all failures are used, the server answers normally again.
+func (s *server) Fail(failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued = append(s.queued, failures...)
+}
+
+// FailAlways makes every request fail until Reset is called
+func (s *server) FailAlways(failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.always = &failure
+}
+
+// Reset removes all injected failures and resets the request counter
+func (s *server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = 0
+	s.queued = nil
+	s.always = nil
+}
+
+// Requests returns the number of requests the server received
+func (s *server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// nextFailure counts the request and returns the failure to apply to it, if any
+func (s *server) nextFailure() *Failure {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	if len(s.queued) > 0 {
+		failure := s.queued[0]
+		s.queued = s.queued[1:]
+		return &failure
+	}
+
+	return s.always
+
+}
+
+// serve answers a request, applying the next failure
+func (s *server) serve(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+
+	failure := s.nextFailure()
+	if failure == nil {
+		handler(w, r)
+		return
+	}
+
+	if failure.Latency > 0 {
+		select {
+		case <-time.After(failure.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if failure.Disconnect {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	if failure.StatusCode == 0 && failure.Body == "" {
+		handler(w, r)
+		return
+	}
+
+	if failure.Body == "" {
+		recorder := httptest.NewRecorder()
+		handler(recorder, r)
+		for key, values := range recorder.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(failure.StatusCode)
+		w.Write(recorder.Body.Bytes())
+		return
+	}
+
+	statusCode := failure.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(statusCode)
+	w.Write([]byte(failure.Body))
+
+}
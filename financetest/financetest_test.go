@@ -0,0 +1,102 @@
+package financetest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
+)
+
+func TestFailures(t *testing.T) {
+
+	s := financetest.NewECBServer()
+	defer s.Close()
+
+	client := finance.NewClient(s.ClientOptions()...)
+
+	type test struct {
+		name         string
+		failure      financetest.Failure
+		expectsError bool
+	}
+
+	var tests = []test{
+		{"none", financetest.Failure{}, false},
+		{"latency", financetest.Latency(10 * time.Millisecond), false},
+		{"server-error", financetest.ServerError(http.StatusServiceUnavailable), true},
+		{"malformed-xml", financetest.MalformedXML(), true},
+		{"disconnect", financetest.Disconnect(), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			// Queued twice as net/http retries an idempotent request once when the connection is closed
+			s.Reset()
+			s.Fail(tc.failure, tc.failure)
+
+			table, err := client.LatestExchangeRates()
+
+			if tc.expectsError {
+				assert.Error(t, err, "error")
+				assert.Nil(t, table, "table")
+			} else {
+				assert.NoError(t, err, "error")
+				assert.NotNil(t, table, "table")
+			}
+
+			s.Reset()
+
+			table, err = client.LatestExchangeRates()
+			assert.NoError(t, err, "recovered")
+			assert.NotNil(t, table, "recovered-table")
+			assert.Equal(t, 1, s.Requests(), "requests")
+
+		})
+	}
+
+}
+
+func TestFailAlways(t *testing.T) {
+
+	s := financetest.NewECBServer()
+	defer s.Close()
+
+	client := finance.NewClient(append(s.ClientOptions(), finance.WithRetryPolicy(finance.RetryPolicy{
+		MaxAttempts: 3,
+		Delay:       time.Millisecond,
+	}))...)
+
+	s.FailAlways(financetest.ServerError(http.StatusBadGateway))
+
+	_, err := client.LatestExchangeRates()
+	assert.Error(t, err, "error")
+	assert.Equal(t, 3, s.Requests(), "requests")
+
+	s.Reset()
+
+	_, err = client.LatestExchangeRates()
+	assert.NoError(t, err, "reset")
+
+}
+
+func TestLatencyTimeout(t *testing.T) {
+
+	s := financetest.NewECBServer()
+	defer s.Close()
+
+	s.Fail(financetest.Latency(time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := finance.NewClient(s.ClientOptions()...).LatestExchangeRatesContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "error")
+
+}
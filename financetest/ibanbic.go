@@ -0,0 +1,132 @@
+package financetest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// The operations of the IBANBIC service emulated by the fake server
+const (
+	IBANBICBankNameOperation   = "BBANtoBANKNAME"   // Returns the name of the bank
+	IBANBICIBANAndBICOperation = "BBANtoIBANandBIC" // Returns the IBAN and the BIC separated by #
+)
+
+// BankAccount is a Belgian bank account known by the fake IBANBIC server
+type BankAccount struct {
+	BBAN     string // The Belgian bank account number, separators are ignored
+	BankName string // The name of the bank
+	IBAN     string // The IBAN, formatted in groups of 4 characters
+	BIC      string // The BIC, formatted the way the IBANBIC service does, e.g. KRED BE BB
+}
+
+// IBANBICServer is a fake ibanbic.be service
+//
+// Accounts which aren't in the fixtures are converted using finance.ConvertBBAN, so every Belgian account number
+// with valid check digits and a known bank code works out of the box. Other numbers are answered with an exception.
+type IBANBICServer struct {
+	server
+
+	accountsMu sync.RWMutex
+	accounts   map[string]BankAccount // The fixtures, keyed by the account number without separators
+}
+
+// NewIBANBICServer starts a fake IBANBIC service which knows the given accounts
+//
+// The server should be closed when it's no longer needed.
+func NewIBANBICServer(accounts ...BankAccount) *IBANBICServer {
+	s := &IBANBICServer{
+		accounts: make(map[string]BankAccount),
+	}
+	for _, account := range accounts {
+		s.Add(account)
+	}
+	s.start(s.handle)
+	return s
+}
+
+// Add adds or replaces an account in the fixtures
+func (s *IBANBICServer) Add(account BankAccount) {
+	s.accountsMu.Lock()
+	defer s.accountsMu.Unlock()
+	s.accounts[stripBBAN(account.BBAN)] = account
+}
+
+// ClientOptions returns the options which make a finance client use the fake IBANBIC service
+func (s *IBANBICServer) ClientOptions() []finance.ClientOption {
+	return []finance.ClientOption{
+		finance.WithIBANBICServiceURL(s.URL),
+	}
+}
+
+// handle answers a request for one of the operations
+func (s *IBANBICServer) handle(w http.ResponseWriter, r *http.Request) {
+
+	operation := strings.TrimPrefix(r.URL.Path, "/")
+	if operation != IBANBICBankNameOperation && operation != IBANBICIBANAndBICOperation {
+		writeIBANBICException(w, "System.InvalidOperationException: Request format is invalid: "+operation+".")
+		return
+	}
+
+	account, ok := s.lookup(r.URL.Query().Get("Value"))
+	if !ok {
+		writeIBANBICException(w, "System.ArgumentException: Invalid bank account number.")
+		return
+	}
+
+	value := account.BankName
+	if operation == IBANBICIBANAndBICOperation {
+		value = account.IBAN + "#" + account.BIC
+	}
+
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	body.WriteString(`<string xmlns="http://tempuri.org/">`)
+	xml.EscapeText(&body, []byte(value))
+	body.WriteString(`</string>`)
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(body.Bytes())
+
+}
+
+// lookup returns the account for a bank account number
+func (s *IBANBICServer) lookup(number string) (BankAccount, bool) {
+
+	s.accountsMu.RLock()
+	account, ok := s.accounts[stripBBAN(number)]
+	s.accountsMu.RUnlock()
+
+	if ok {
+		return account, true
+	}
+
+	info, err := finance.ConvertBBAN(number)
+	if err != nil {
+		return BankAccount{}, false
+	}
+
+	return BankAccount{
+		BBAN:     info.BBAN,
+		BankName: info.BankName,
+		IBAN:     info.IBAN,
+		BIC:      info.BIC,
+	}, true
+
+}
+
+// writeIBANBICException answers with an exception the way the ASP.NET service does
+func writeIBANBICException(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte(message + "\n   at IBANBIC.BBANtoIBANandBIC(String Value)\n"))
+}
+
+// stripBBAN removes the separators from a bank account number
+func stripBBAN(number string) string {
+	return strings.NewReplacer("-", "", ".", "", " ", "", "/", "").Replace(strings.TrimSpace(number))
+}
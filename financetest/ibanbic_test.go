@@ -0,0 +1,59 @@
+package financetest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
+)
+
+func TestIBANBICServer(t *testing.T) {
+
+	s := financetest.NewIBANBICServer(
+		financetest.BankAccount{BBAN: "001-2345678-90", BankName: "Test Bank", IBAN: "BE00 0012 3456 7890", BIC: "TEST BE BB"},
+	)
+	defer s.Close()
+
+	client := finance.NewClient(s.ClientOptions()...)
+
+	type test struct {
+		number           string
+		expectedBankName string
+		expectedIBAN     string
+		expectedBIC      string
+		expectsError     bool
+	}
+
+	var tests = []test{
+		{"738-1202561-74", "KBC Bank", "BE16 7381 2025 6174", "KRED BE BB", false},
+		{"001234567890", "Test Bank", "BE00 0012 3456 7890", "TEST BE BB", false},
+		{"738-1202561-75", "", "", "", true},
+		{"738-AAAAAAA-74", "", "", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.number, func(t *testing.T) {
+
+			info, err := client.CheckIBAN(tc.number)
+
+			if tc.expectsError {
+				assert.Error(t, err, "error")
+				assert.Nil(t, info, "info")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			if assert.NotNil(t, info, "info") {
+				assert.Equal(t, tc.expectedBankName, info.BankName, "bank-name")
+				assert.Equal(t, tc.expectedIBAN, info.IBAN, "iban")
+				assert.Equal(t, tc.expectedBIC, info.BIC, "bic")
+			}
+
+		})
+	}
+
+	assert.Equal(t, 6, s.Requests(), "requests")
+
+}
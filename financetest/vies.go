@@ -0,0 +1,258 @@
+package financetest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+// VATRecord is a VAT number known by the fake VIES server
+type VATRecord struct {
+	CountryCode string // The country code used by VIES, e.g. BE or EL
+	VATNumber   string // The VAT number without the country code
+	Valid       bool   // Whether VIES reports the number as valid
+	Name        string // The name of the trader
+	Address     string // The address of the trader
+	Fault       string // When set, VIES answers with this fault instead, e.g. finance.VIESFaultMSUnavailable
+}
+
+// VIESServer is a fake VIES service which speaks the checkVat and checkVatApprox SOAP operations
+//
+// Numbers which aren't in the fixtures are reported as not valid. Numbers with an unknown country code or a format
+// which doesn't match the member state are answered with an INVALID_INPUT fault, just like VIES does.
+type VIESServer struct {
+	server
+
+	recordsMu sync.RWMutex
+	records   map[string]VATRecord // The fixtures, keyed by country code and number
+}
+
+// viesRequest contains the fields of a checkVat or checkVatApprox request
+type viesRequest struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		CheckVAT       *viesCheck `xml:"checkVat"`
+		CheckVATApprox *viesCheck `xml:"checkVatApprox"`
+	} `xml:"Body"`
+}
+
+// viesCheck contains the fields of a checkVat or checkVatApprox operation
+type viesCheck struct {
+	CountryCode       string `xml:"countryCode"`
+	VATNumber         string `xml:"vatNumber"`
+	TraderName        string `xml:"traderName"`
+	TraderCompanyType string `xml:"traderCompanyType"`
+	TraderStreet      string `xml:"traderStreet"`
+	TraderPostcode    string `xml:"traderPostcode"`
+	TraderCity        string `xml:"traderCity"`
+}
+
+// NewVIESServer starts a fake VIES service which knows the given VAT numbers
+//
+// The server should be closed when it's no longer needed.
+func NewVIESServer(records ...VATRecord) *VIESServer {
+	s := &VIESServer{
+		records: make(map[string]VATRecord),
+	}
+	for _, record := range records {
+		s.Add(record)
+	}
+	s.start(s.handle)
+	return s
+}
+
+// Add adds or replaces a VAT number in the fixtures
+func (s *VIESServer) Add(record VATRecord) {
+	s.recordsMu.Lock()
+	defer s.recordsMu.Unlock()
+	s.records[strings.ToUpper(record.CountryCode+record.VATNumber)] = record
+}
+
+// ClientOptions returns the options which make a finance client use the fake VIES service
+func (s *VIESServer) ClientOptions() []finance.ClientOption {
+	return []finance.ClientOption{
+		finance.WithVATServiceURL(s.URL),
+	}
+}
+
+// VIESFault returns a failure which answers with a SOAP fault with the given fault string
+func VIESFault(fault string) Failure {
+	return Failure{
+		StatusCode: http.StatusInternalServerError,
+		Body:       viesFaultEnvelope(fault),
+	}
+}
+
+// handle answers a SOAP request
+func (s *VIESServer) handle(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil || r.Method != http.MethodPost {
+		writeVIESFault(w, finance.VIESFaultInvalidInput)
+		return
+	}
+
+	var request viesRequest
+	if err := xml.Unmarshal(body, &request); err != nil {
+		writeVIESFault(w, finance.VIESFaultInvalidInput)
+		return
+	}
+
+	check := request.Body.CheckVAT
+	approx := request.Body.CheckVATApprox != nil
+	if approx {
+		check = request.Body.CheckVATApprox
+	}
+
+	if check == nil {
+		writeVIESFault(w, finance.VIESFaultInvalidInput)
+		return
+	}
+
+	record, fault := s.lookup(strings.TrimSpace(check.CountryCode), strings.TrimSpace(check.VATNumber))
+	if fault != "" {
+		writeVIESFault(w, fault)
+		return
+	}
+
+	if approx {
+		w.Write(viesApproxResponse(record, check, "WAPIAAAA"+strconv.Itoa(s.Requests())))
+		return
+	}
+
+	w.Write(viesResponse(record))
+
+}
+
+// lookup returns the record for a VAT number or the fault VIES would answer with
+func (s *VIESServer) lookup(countryCode string, vatNumber string) (VATRecord, string) {
+
+	s.recordsMu.RLock()
+	record, ok := s.records[strings.ToUpper(countryCode+vatNumber)]
+	s.recordsMu.RUnlock()
+
+	if ok {
+		return record, record.Fault
+	}
+
+	err := finance.ValidateVAT(countryCode + vatNumber)
+	if errors.Is(err, finance.ErrVATUnknownCountry) || errors.Is(err, finance.ErrVATInvalidFormat) || errors.Is(err, finance.ErrVATNumberTooShort) {
+		return record, finance.VIESFaultInvalidInput
+	}
+
+	return VATRecord{CountryCode: countryCode, VATNumber: vatNumber}, ""
+
+}
+
+// viesResponse builds the response of a checkVat request
+func viesResponse(record VATRecord) []byte {
+
+	var body bytes.Buffer
+	body.WriteString(`<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Header/><env:Body>`)
+	body.WriteString(`<ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">`)
+	writeXMLElement(&body, "ns2:countryCode", record.CountryCode)
+	writeXMLElement(&body, "ns2:vatNumber", record.VATNumber)
+	writeXMLElement(&body, "ns2:requestDate", viesRequestDate())
+	writeXMLElement(&body, "ns2:valid", strconv.FormatBool(record.Valid))
+	writeXMLElement(&body, "ns2:name", viesValue(record.Valid, record.Name))
+	writeXMLElement(&body, "ns2:address", viesValue(record.Valid, record.Address))
+	body.WriteString(`</ns2:checkVatResponse></env:Body></env:Envelope>`)
+
+	return body.Bytes()
+
+}
+
+// viesApproxResponse builds the response of a checkVatApprox request including the match indicators
+func viesApproxResponse(record VATRecord, check *viesCheck, requestIdentifier string) []byte {
+
+	var body bytes.Buffer
+	body.WriteString(`<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Header/><env:Body>`)
+	body.WriteString(`<ns2:checkVatApproxResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">`)
+	writeXMLElement(&body, "ns2:countryCode", record.CountryCode)
+	writeXMLElement(&body, "ns2:vatNumber", record.VATNumber)
+	writeXMLElement(&body, "ns2:requestDate", viesRequestDate())
+	writeXMLElement(&body, "ns2:valid", strconv.FormatBool(record.Valid))
+	writeXMLElement(&body, "ns2:traderName", viesValue(record.Valid, record.Name))
+	writeXMLElement(&body, "ns2:traderAddress", viesValue(record.Valid, record.Address))
+	if record.Valid && check.TraderName != "" {
+		writeXMLElement(&body, "ns2:traderNameMatch", viesMatch(check.TraderName, record.Name))
+	}
+	if record.Valid && check.TraderCompanyType != "" {
+		writeXMLElement(&body, "ns2:traderCompanyTypeMatch", strconv.Itoa(int(finance.VIESMatchNotProcessed)))
+	}
+	if record.Valid && check.TraderStreet != "" {
+		writeXMLElement(&body, "ns2:traderStreetMatch", viesContainsMatch(record.Address, check.TraderStreet))
+	}
+	if record.Valid && check.TraderPostcode != "" {
+		writeXMLElement(&body, "ns2:traderPostcodeMatch", viesContainsMatch(record.Address, check.TraderPostcode))
+	}
+	if record.Valid && check.TraderCity != "" {
+		writeXMLElement(&body, "ns2:traderCityMatch", viesContainsMatch(record.Address, check.TraderCity))
+	}
+	writeXMLElement(&body, "ns2:requestIdentifier", requestIdentifier)
+	body.WriteString(`</ns2:checkVatApproxResponse></env:Body></env:Envelope>`)
+
+	return body.Bytes()
+
+}
+
+// viesFaultEnvelope builds a SOAP fault with the given fault string
+func viesFaultEnvelope(fault string) string {
+	var body bytes.Buffer
+	body.WriteString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode>`)
+	writeXMLElement(&body, "faultstring", fault)
+	body.WriteString(`</soap:Fault></soap:Body></soap:Envelope>`)
+	return body.String()
+}
+
+// writeVIESFault answers with a SOAP fault, VIES uses status 500 for all its faults
+func writeVIESFault(w http.ResponseWriter, fault string) {
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte(viesFaultEnvelope(fault)))
+}
+
+// viesRequestDate returns the request date the way VIES formats it
+func viesRequestDate() string {
+	return time.Now().Format("2006-01-02-07:00")
+}
+
+// viesValue returns the value VIES sends for a trader detail, which is --- for invalid numbers
+func viesValue(valid bool, value string) string {
+	if !valid || value == "" {
+		return "---"
+	}
+	return value
+}
+
+// viesMatch returns the match indicator for a trader detail which should be equal
+func viesMatch(requested string, registered string) string {
+	if strings.EqualFold(strings.TrimSpace(requested), strings.TrimSpace(registered)) {
+		return strconv.Itoa(int(finance.VIESMatchValid))
+	}
+	return strconv.Itoa(int(finance.VIESMatchInvalid))
+}
+
+// viesContainsMatch returns the match indicator for a trader detail which should be part of the address
+func viesContainsMatch(address string, requested string) string {
+	if strings.Contains(strings.ToUpper(address), strings.ToUpper(strings.TrimSpace(requested))) {
+		return strconv.Itoa(int(finance.VIESMatchValid))
+	}
+	return strconv.Itoa(int(finance.VIESMatchInvalid))
+}
+
+// writeXMLElement writes an element with an escaped text value
+func writeXMLElement(body *bytes.Buffer, name string, value string) {
+	body.WriteString("<" + name + ">")
+	xml.EscapeText(body, []byte(value))
+	body.WriteString("</" + name + ">")
+}
@@ -0,0 +1,94 @@
+package financetest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
+)
+
+func TestVIESServer(t *testing.T) {
+
+	s := financetest.NewVIESServer(
+		financetest.VATRecord{CountryCode: "BE", VATNumber: "0836157420", Valid: true, Name: "SRL APPLE RETAIL BELGIUM", Address: "Avenue du Port 86C/204\n1000 Bruxelles"},
+		financetest.VATRecord{CountryCode: "DE", VATNumber: "123456788", Fault: finance.VIESFaultMSUnavailable},
+	)
+	defer s.Close()
+
+	client := finance.NewClient(s.ClientOptions()...)
+
+	type test struct {
+		name          string
+		vatNumber     string
+		expectedValid bool
+		expectedName  string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"valid", "BE 0836.157.420", true, "SRL APPLE RETAIL BELGIUM", nil},
+		{"unknown", "BE0000000097", false, "", nil},
+		{"invalid-checksum", "BE0836157421", false, "", nil},
+		{"invalid-format", "BE12", false, "", finance.ErrVATnumberNotValid},
+		{"unknown-country", "XX123456789", false, "", finance.ErrVIESInvalidInput},
+		{"fault", "DE123456788", false, "", finance.ErrVIESMSUnavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			info, err := client.CheckVAT(tc.vatNumber)
+
+			if tc.expectedError != nil {
+				assert.Nil(t, info, "info")
+				assert.True(t, errors.Is(err, tc.expectedError), "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			if assert.NotNil(t, info, "info") {
+				assert.Equal(t, tc.expectedValid, info.IsValid, "valid")
+				assert.Equal(t, tc.expectedName, info.Name, "name")
+			}
+
+		})
+	}
+
+	s.Fail(financetest.VIESFault(finance.VIESFaultTimeout))
+
+	_, err := client.CheckVAT("BE0836157420")
+	assert.True(t, errors.Is(err, finance.ErrVIESTimeout), "injected-fault")
+
+}
+
+func TestVIESServerApprox(t *testing.T) {
+
+	s := financetest.NewVIESServer(
+		financetest.VATRecord{CountryCode: "BE", VATNumber: "0836157420", Valid: true, Name: "SRL APPLE RETAIL BELGIUM", Address: "Avenue du Port 86C/204\n1000 Bruxelles"},
+	)
+	defer s.Close()
+
+	client := finance.NewClient(s.ClientOptions()...)
+
+	info, err := client.CheckVATApprox(finance.VATApproxRequest{
+		VATNumber:          "BE0836157420",
+		RequesterVATNumber: "BE0836157420",
+		TraderName:         "srl apple retail belgium",
+		TraderCity:         "Antwerpen",
+	})
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, info, "info") {
+		assert.True(t, info.IsValid, "valid")
+		assert.Equal(t, "SRL APPLE RETAIL BELGIUM", info.TraderName, "trader-name")
+		assert.Equal(t, finance.VIESMatchValid, info.TraderNameMatch, "name-match")
+		assert.Equal(t, finance.VIESMatchInvalid, info.TraderCityMatch, "city-match")
+		assert.Equal(t, finance.VIESMatchUnknown, info.TraderStreetMatch, "street-match")
+		assert.NotEmpty(t, info.RequestIdentifier, "request-identifier")
+		assert.False(t, info.RequestDate.IsZero(), "request-date")
+	}
+
+}
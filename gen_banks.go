@@ -0,0 +1,315 @@
+//go:build ignore
+// +build ignore
+
+// gen_banks downloads the list of bank identification codes published by the National Bank of Belgium and writes
+// the protocol code ranges used by ConvertBBAN to bban_banks.go
+//
+// Run it with go generate whenever the NBB publishes a new list.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nbbListURL is the location of the full list of bank identification codes
+const nbbListURL = "https://www.nbb.be/doc/be/be/protocol/r_fulllist_of_codes_current.xlsx"
+
+// outputFile is the file the ranges are written to
+const outputFile = "bban_banks.go"
+
+// emptyValues are the values the NBB uses for a BIC or bank name which isn't assigned
+var emptyValues = map[string]bool{"": true, "-": true, "--": true, "NAV": true, "NAP": true, "VRIJ": true, "LIBRE": true, "FREE": true}
+
+// bank is a range of protocol codes
+type bank struct {
+	from     int
+	to       int
+	bic      string
+	bankName string
+}
+
+// sharedStrings is the table of strings used by the cells of a workbook
+type sharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+// worksheet contains the rows of a sheet of a workbook
+type worksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func main() {
+
+	source := nbbListURL
+	if len(os.Args) > 1 {
+		source = os.Args[1]
+	}
+
+	rawData, err := read(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rows, err := readWorkbook(rawData)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	banks := parseBanks(rows)
+	if len(banks) == 0 {
+		log.Fatal("no protocol codes found in " + source)
+	}
+
+	if err := ioutil.WriteFile(outputFile, render(banks, source), 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Wrote", len(banks), "protocol code ranges to", outputFile)
+
+}
+
+// read returns the list from a URL or a local file
+func read(source string) ([]byte, error) {
+
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return ioutil.ReadFile(source)
+	}
+
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP status %d", source, resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+
+}
+
+// readWorkbook returns the cell values of the first sheet of an xlsx workbook
+func readWorkbook(rawData []byte) ([][]string, error) {
+
+	archive, err := zip.NewReader(bytes.NewReader(rawData), int64(len(rawData)))
+	if err != nil {
+		return nil, err
+	}
+
+	var strs sharedStrings
+	if err := readXML(archive, "xl/sharedStrings.xml", &strs, true); err != nil {
+		return nil, err
+	}
+
+	table := make([]string, len(strs.Items))
+	for i, item := range strs.Items {
+		table[i] = item.Text
+		for _, run := range item.Runs {
+			table[i] += run.Text
+		}
+	}
+
+	var sheet worksheet
+	if err := readXML(archive, "xl/worksheets/sheet1.xml", &sheet, false); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+
+	for _, row := range sheet.Rows {
+
+		var values []string
+
+		for i, cell := range row.Cells {
+
+			column := columnIndex(cell.Ref)
+			if column < 0 {
+				column = i
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(table) {
+					return nil, fmt.Errorf("invalid shared string in cell %s", cell.Ref)
+				}
+				values[column] = table[index]
+			case "inlineStr":
+				values[column] = cell.Inline
+			default:
+				values[column] = cell.Value
+			}
+
+		}
+
+		rows = append(rows, values)
+
+	}
+
+	return rows, nil
+
+}
+
+// readXML decodes a file of the workbook
+func readXML(archive *zip.Reader, name string, target interface{}, optional bool) error {
+
+	for _, file := range archive.File {
+
+		if file.Name != name {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		return xml.NewDecoder(r).Decode(target)
+
+	}
+
+	if optional {
+		return nil
+	}
+
+	return io.ErrUnexpectedEOF
+
+}
+
+// columnIndex returns the zero-based column of a cell reference such as C12
+func columnIndex(ref string) int {
+
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+
+	return column - 1
+
+}
+
+// parseBanks returns the assigned protocol code ranges, sorted by protocol code
+//
+// Each row contains the first and last protocol code, the BIC and the name of the bank in Dutch, French, German and
+// English. The first name which is filled in is used.
+func parseBanks(rows [][]string) []bank {
+
+	var banks []bank
+
+	for _, row := range rows {
+
+		if len(row) < 4 {
+			continue
+		}
+
+		from, err1 := parseCode(row[0])
+		to, err2 := parseCode(row[1])
+		if err1 != nil || err2 != nil || from > to {
+			continue
+		}
+
+		bic := clean(strings.Replace(row[2], " ", "", -1))
+
+		bankName := ""
+		for _, name := range row[3:] {
+			if bankName = clean(name); bankName != "" {
+				break
+			}
+		}
+
+		if bic == "" && bankName == "" {
+			continue
+		}
+
+		banks = append(banks, bank{from: from, to: to, bic: strings.ToUpper(bic), bankName: bankName})
+
+	}
+
+	sort.SliceStable(banks, func(i, j int) bool {
+		return banks[i].from < banks[j].from
+	})
+
+	return banks
+
+}
+
+// parseCode parses a protocol code, which can be stored as text or as a number
+func parseCode(value string) (int, error) {
+
+	code, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || code < 0 || code > 999 || code != float64(int(code)) {
+		return 0, fmt.Errorf("invalid protocol code: %s", value)
+	}
+
+	return int(code), nil
+
+}
+
+// clean trims a value and returns an empty string for the values the NBB uses for unassigned codes
+func clean(value string) string {
+
+	value = strings.Join(strings.Fields(value), " ")
+	if emptyValues[strings.ToUpper(value)] {
+		return ""
+	}
+
+	return value
+
+}
+
+// render returns the formatted Go source containing the ranges
+func render(banks []bank, source string) []byte {
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by go run gen_banks.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package finance\n\n")
+	buf.WriteString("// belgianBanks contains the protocol code ranges of the National Bank of Belgium, sorted by protocol code\n")
+	buf.WriteString("//\n")
+	buf.WriteString("// The protocol code consists of the first three digits of a Belgian bank account number. The ranges are taken\n")
+	buf.WriteString("// from " + source + "\n")
+	buf.WriteString("var belgianBanks = []belgianBank{\n")
+	for _, b := range banks {
+		fmt.Fprintf(&buf, "\t{%d, %d, %q, %q},\n", b.from, b.to, b.bic, b.bankName)
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return formatted
+
+}
@@ -0,0 +1,311 @@
+package finance
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrIBANTooShort is the error returned when the IBAN is too short to contain a country code and check digits
+	ErrIBANTooShort = errors.New("IBAN is too short")
+
+	// ErrIBANInvalidCharacters is the error returned when the IBAN contains characters other than letters and digits
+	ErrIBANInvalidCharacters = errors.New("IBAN contains invalid characters")
+
+	// ErrIBANUnknownCountry is the error returned when the country code isn't part of the IBAN registry
+	ErrIBANUnknownCountry = errors.New("IBAN country code is unknown")
+
+	// ErrIBANInvalidLength is the error returned when the IBAN doesn't have the length defined for its country
+	ErrIBANInvalidLength = errors.New("IBAN has an invalid length")
+
+	// ErrIBANInvalidFormat is the error returned when the BBAN part doesn't match the structure defined for its country
+	ErrIBANInvalidFormat = errors.New("IBAN has an invalid BBAN structure")
+
+	// ErrIBANInvalidChecksum is the error returned when the IBAN check digits are wrong
+	ErrIBANInvalidChecksum = errors.New("IBAN checksum is invalid")
+)
+
+// IBANError is the error returned when an IBAN fails validation
+//
+// Use errors.Is with one of the ErrIBAN* errors to find out which rule failed.
+type IBANError struct {
+	IBAN   string // The IBAN in electronic format
+	Rule   error  // The rule which failed, one of the ErrIBAN* errors
+	Detail string // Additional information about the failure
+}
+
+// ibanCountry contains the IBAN structure of a country as defined in the SWIFT IBAN registry
+type ibanCountry struct {
+	Name       string // The name of the country
+	Length     int    // The total length of the IBAN
+	BBANFormat string // The structure of the BBAN in SWIFT notation, e.g. 3!n7!n2!n
+}
+
+// ibanRegistry contains the IBAN structure of the countries in the SWIFT IBAN registry
+var ibanRegistry = map[string]ibanCountry{
+	"AD": {"Andorra", 24, "4!n4!n12!c"},
+	"AE": {"United Arab Emirates", 23, "3!n16!n"},
+	"AL": {"Albania", 28, "8!n16!c"},
+	"AT": {"Austria", 20, "5!n11!n"},
+	"AZ": {"Azerbaijan", 28, "4!a20!c"},
+	"BA": {"Bosnia and Herzegovina", 20, "3!n3!n8!n2!n"},
+	"BE": {"Belgium", 16, "3!n7!n2!n"},
+	"BG": {"Bulgaria", 22, "4!a4!n2!n8!c"},
+	"BH": {"Bahrain", 22, "4!a14!c"},
+	"BI": {"Burundi", 27, "5!n5!n11!n2!n"},
+	"BR": {"Brazil", 29, "8!n5!n10!n1!a1!c"},
+	"BY": {"Belarus", 28, "4!c4!n16!c"},
+	"CH": {"Switzerland", 21, "5!n12!c"},
+	"CR": {"Costa Rica", 22, "4!n14!n"},
+	"CY": {"Cyprus", 28, "3!n5!n16!c"},
+	"CZ": {"Czechia", 24, "4!n6!n10!n"},
+	"DE": {"Germany", 22, "8!n10!n"},
+	"DJ": {"Djibouti", 27, "5!n5!n11!n2!n"},
+	"DK": {"Denmark", 18, "4!n9!n1!n"},
+	"DO": {"Dominican Republic", 28, "4!c20!n"},
+	"EE": {"Estonia", 20, "2!n14!n"},
+	"EG": {"Egypt", 29, "4!n4!n17!n"},
+	"ES": {"Spain", 24, "4!n4!n1!n1!n10!n"},
+	"FI": {"Finland", 18, "3!n11!n"},
+	"FK": {"Falkland Islands", 18, "2!a12!n"},
+	"FO": {"Faroe Islands", 18, "4!n9!n1!n"},
+	"FR": {"France", 27, "5!n5!n11!c2!n"},
+	"GB": {"United Kingdom", 22, "4!a6!n8!n"},
+	"GE": {"Georgia", 22, "2!a16!n"},
+	"GI": {"Gibraltar", 23, "4!a15!c"},
+	"GL": {"Greenland", 18, "4!n9!n1!n"},
+	"GR": {"Greece", 27, "3!n4!n16!c"},
+	"GT": {"Guatemala", 28, "4!c20!c"},
+	"HR": {"Croatia", 21, "7!n10!n"},
+	"HU": {"Hungary", 28, "3!n4!n1!n15!n1!n"},
+	"IE": {"Ireland", 22, "4!a6!n8!n"},
+	"IL": {"Israel", 23, "3!n3!n13!n"},
+	"IQ": {"Iraq", 23, "4!a3!n12!n"},
+	"IS": {"Iceland", 26, "4!n2!n6!n10!n"},
+	"IT": {"Italy", 27, "1!a5!n5!n12!c"},
+	"JO": {"Jordan", 30, "4!a4!n18!c"},
+	"KW": {"Kuwait", 30, "4!a22!c"},
+	"KZ": {"Kazakhstan", 20, "3!n13!c"},
+	"LB": {"Lebanon", 28, "4!n20!c"},
+	"LC": {"Saint Lucia", 32, "4!a24!c"},
+	"LI": {"Liechtenstein", 21, "5!n12!c"},
+	"LT": {"Lithuania", 20, "5!n11!n"},
+	"LU": {"Luxembourg", 20, "3!n13!c"},
+	"LV": {"Latvia", 21, "4!a13!c"},
+	"LY": {"Libya", 25, "3!n3!n15!n"},
+	"MC": {"Monaco", 27, "5!n5!n11!c2!n"},
+	"MD": {"Moldova", 24, "2!c18!c"},
+	"ME": {"Montenegro", 22, "3!n13!n2!n"},
+	"MK": {"North Macedonia", 19, "3!n10!c2!n"},
+	"MN": {"Mongolia", 20, "4!n12!n"},
+	"MR": {"Mauritania", 27, "5!n5!n11!n2!n"},
+	"MT": {"Malta", 31, "4!a5!n18!c"},
+	"MU": {"Mauritius", 30, "4!a2!n2!n12!n3!n3!a"},
+	"NI": {"Nicaragua", 28, "4!a20!n"},
+	"NL": {"Netherlands", 18, "4!a10!n"},
+	"NO": {"Norway", 15, "4!n6!n1!n"},
+	"OM": {"Oman", 23, "3!n16!c"},
+	"PK": {"Pakistan", 24, "4!a16!c"},
+	"PL": {"Poland", 28, "8!n16!n"},
+	"PS": {"Palestine", 29, "4!a21!c"},
+	"PT": {"Portugal", 25, "4!n4!n11!n2!n"},
+	"QA": {"Qatar", 29, "4!a21!c"},
+	"RO": {"Romania", 24, "4!a16!c"},
+	"RS": {"Serbia", 22, "3!n13!n2!n"},
+	"RU": {"Russia", 33, "9!n5!n15!c"},
+	"SA": {"Saudi Arabia", 24, "2!n18!c"},
+	"SC": {"Seychelles", 31, "4!a2!n2!n16!n3!a"},
+	"SD": {"Sudan", 18, "2!n12!n"},
+	"SE": {"Sweden", 24, "3!n16!n1!n"},
+	"SI": {"Slovenia", 19, "5!n8!n2!n"},
+	"SK": {"Slovakia", 24, "4!n6!n10!n"},
+	"SM": {"San Marino", 27, "1!a5!n5!n12!c"},
+	"SO": {"Somalia", 23, "4!n3!n12!n"},
+	"ST": {"Sao Tome and Principe", 25, "4!n4!n11!n2!n"},
+	"SV": {"El Salvador", 28, "4!a20!n"},
+	"TL": {"Timor-Leste", 23, "3!n14!n2!n"},
+	"TN": {"Tunisia", 24, "2!n3!n13!n2!n"},
+	"TR": {"Turkey", 26, "5!n1!n16!c"},
+	"UA": {"Ukraine", 29, "6!n19!c"},
+	"VA": {"Vatican City", 22, "3!n15!n"},
+	"VG": {"British Virgin Islands", 24, "4!a16!n"},
+	"XK": {"Kosovo", 20, "4!n10!n2!n"},
+	"YE": {"Yemen", 30, "4!a4!n18!c"},
+}
+
+// Error returns the error message
+func (e *IBANError) Error() string {
+	msg := e.Rule.Error() + ": " + e.IBAN
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the rule which failed
+func (e *IBANError) Unwrap() error {
+	return e.Rule
+}
+
+// ValidateIBAN checks the country code, the length, the BBAN structure and the checksum of an IBAN without using the network
+//
+// Spaces are ignored and lowercase letters are accepted. When validation fails, an *IBANError is returned.
+func ValidateIBAN(iban string) error {
+
+	iban = NormalizeIBAN(iban)
+
+	if len(iban) < 4 {
+		return &IBANError{IBAN: iban, Rule: ErrIBANTooShort}
+	}
+
+	for _, r := range iban {
+		if r > unicode.MaxASCII || !(unicode.IsDigit(r) || unicode.IsUpper(r)) {
+			return &IBANError{IBAN: iban, Rule: ErrIBANInvalidCharacters, Detail: "unexpected " + strconv.QuoteRune(r)}
+		}
+	}
+
+	country, ok := ibanRegistry[iban[0:2]]
+	if !ok {
+		return &IBANError{IBAN: iban, Rule: ErrIBANUnknownCountry, Detail: iban[0:2]}
+	}
+
+	if len(iban) != country.Length {
+		return &IBANError{
+			IBAN:   iban,
+			Rule:   ErrIBANInvalidLength,
+			Detail: country.Name + " IBANs have " + strconv.Itoa(country.Length) + " characters, got " + strconv.Itoa(len(iban)),
+		}
+	}
+
+	if !isDigits(iban[2:4]) {
+		return &IBANError{IBAN: iban, Rule: ErrIBANInvalidFormat, Detail: "check digits should be numeric"}
+	}
+
+	if !matchesBBANFormat(iban[4:], country.BBANFormat) {
+		return &IBANError{IBAN: iban, Rule: ErrIBANInvalidFormat, Detail: "expected " + country.BBANFormat}
+	}
+
+	if ibanMod97(iban) != 1 {
+		return &IBANError{IBAN: iban, Rule: ErrIBANInvalidChecksum}
+	}
+
+	return nil
+
+}
+
+// NormalizeIBAN converts an IBAN to its electronic format by removing the spaces and converting it to uppercase
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// FormatIBAN converts an IBAN to its print format, in groups of four characters separated by spaces
+func FormatIBAN(iban string) string {
+
+	iban = NormalizeIBAN(iban)
+
+	var sb strings.Builder
+	for i, r := range iban {
+		if i > 0 && i%4 == 0 {
+			sb.WriteRune(' ')
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+
+}
+
+// matchesBBANFormat checks if a BBAN matches a structure in SWIFT notation
+//
+// The structure consists of blocks like 4!n, where the number is the fixed length and the letter the character
+// type: n for digits, a for uppercase letters and c for uppercase letters and digits.
+func matchesBBANFormat(bban string, format string) bool {
+
+	pos := 0
+
+	for len(format) > 0 {
+
+		sep := strings.IndexByte(format, '!')
+		if sep < 1 || sep+1 >= len(format) {
+			return false
+		}
+
+		length, err := strconv.Atoi(format[:sep])
+		if err != nil || pos+length > len(bban) {
+			return false
+		}
+
+		kind := format[sep+1]
+		for _, r := range bban[pos : pos+length] {
+			switch kind {
+			case 'n':
+				if r < '0' || r > '9' {
+					return false
+				}
+			case 'a':
+				if r < 'A' || r > 'Z' {
+					return false
+				}
+			case 'c':
+				if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+
+		pos += length
+		format = format[sep+2:]
+
+	}
+
+	return pos == len(bban)
+
+}
+
+// ibanMod97 computes the ISO 7064 MOD 97-10 remainder of an IBAN in electronic format
+func ibanMod97(iban string) int {
+
+	rearranged := iban[4:] + iban[0:4]
+
+	var digits strings.Builder
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	return mod97(digits.String())
+
+}
+
+// mod97 returns the remainder of dividing a string of digits by 97
+func mod97(digits string) int {
+	remainder := 0
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		remainder = (remainder*10 + int(r-'0')) % 97
+	}
+	return remainder
+}
+
+// isDigits returns true when the string is non-empty and only contains the digits 0-9
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
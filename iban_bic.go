@@ -1,9 +1,8 @@
 package finance
 
 import (
+	"context"
 	"encoding/xml"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -15,6 +14,8 @@ import (
 const DefaultIBANBICServiceURL = "https://www.ibanbic.be/IBANBIC.asmx"
 
 // IBANBICServiceURL is the SOAP URL to be used when checking a bank account number
+//
+// It's used by the package-level functions. Use NewClient with WithIBANBICServiceURL to configure a client of your own.
 var IBANBICServiceURL = DefaultIBANBICServiceURL
 
 // DefaultIBANBICTimeout is the default timeout to use when checking the bank account number
@@ -34,6 +35,24 @@ var (
 	ErrIBANBICServiceError = "IBANBIC service returns an error: "
 )
 
+// IBANBICError is the error returned when the IBANBIC service rejects a bank account number with an exception
+//
+// The service only answers with an exception when it can't handle the number, so an IBANBICError also matches
+// ErrIBANBICInvalidInput when using errors.Is.
+type IBANBICError struct {
+	Message string // The first line of the exception as returned by the service
+}
+
+// Error returns the error message
+func (e *IBANBICError) Error() string {
+	return ErrIBANBICServiceError + e.Message
+}
+
+// Is returns true for ErrIBANBICInvalidInput
+func (e *IBANBICError) Is(target error) bool {
+	return target == ErrIBANBICInvalidInput
+}
+
 // IBANBICInfo contains the info about a Belgian Bank Account number
 type IBANBICInfo struct {
 	BBAN     string // The Belgian Bank Account Number
@@ -44,6 +63,21 @@ type IBANBICInfo struct {
 
 // CheckIBAN checks the Bank Account Number and returns the IBAN and BIC information
 func CheckIBAN(number string) (*IBANBICInfo, error) {
+	return defaultClient().CheckIBAN(number)
+}
+
+// CheckIBANContext checks the Bank Account Number using the given context and returns the IBAN and BIC information
+func CheckIBANContext(ctx context.Context, number string) (*IBANBICInfo, error) {
+	return defaultClient().CheckIBANContext(ctx, number)
+}
+
+// CheckIBAN checks the Bank Account Number and returns the IBAN and BIC information
+func (c *Client) CheckIBAN(number string) (*IBANBICInfo, error) {
+	return c.CheckIBANContext(context.Background(), number)
+}
+
+// CheckIBANContext checks the Bank Account Number using the given context and returns the IBAN and BIC information
+func (c *Client) CheckIBANContext(ctx context.Context, number string) (*IBANBICInfo, error) {
 
 	if len(number) == 0 {
 		return nil, ErrIBANBICInvalidInput
@@ -53,13 +87,17 @@ func CheckIBAN(number string) (*IBANBICInfo, error) {
 		BBAN: number,
 	}
 
-	bankName, err := performIBANBICRequest("BBANtoBANKNAME", number)
+	bankName, err := c.performIBANBICRequest(ctx, "BBANtoBANKNAME", number)
 	if err != nil {
 		return nil, err
 	}
 	result.BankName = bankName
 
-	ibanAndBic, err := performIBANBICRequest("BBANtoIBANandBIC", number)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ibanAndBic, err := c.performIBANBICRequest(ctx, "BBANtoIBANandBIC", number)
 	if err != nil {
 		return nil, err
 	}
@@ -76,31 +114,27 @@ func CheckIBAN(number string) (*IBANBICInfo, error) {
 
 }
 
-func performIBANBICRequest(action string, value string) (string, error) {
+func (c *Client) performIBANBICRequest(ctx context.Context, action string, value string) (string, error) {
 
-	url := IBANBICServiceURL + "/" + url.PathEscape(action) + "?Value=" + url.QueryEscape(value)
+	url := c.ibanbicServiceURL + "/" + url.PathEscape(action) + "?Value=" + url.QueryEscape(value)
 
-	client := http.Client{
-		Timeout: VATTimeout,
-	}
+	xmlRes, err := c.doRequest(ctx, c.ibanbicTimeout, "GET", url, "", nil, retryIBANBICResponse)
 
-	res, err := client.Get(url)
-	if err != nil {
-		return "", ErrIBANBICServiceUnreachable
+	// The service reports invalid input with an exception and an HTTP 500 status
+	xmlString := string(xmlRes)
+	if _, ok := err.(*StatusError); (ok || err == nil) && isIBANBICException(xmlRes) {
+		exceptionParts := strings.Split(xmlString, "\n")
+		return "", &IBANBICError{Message: strings.TrimSpace(exceptionParts[0])}
 	}
-	defer res.Body.Close()
 
-	xmlRes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
+		switch err.(type) {
+		case *unreachableError, *StatusError:
+			return "", ErrIBANBICServiceUnreachable
+		}
 		return "", err
 	}
 
-	xmlString := string(xmlRes)
-	if strings.Contains(xmlString, "Exception") {
-		exceptionParts := strings.Split(xmlString, "\n")
-		return "", errors.New(ErrIBANBICServiceError + strings.TrimSpace(exceptionParts[0]))
-	}
-
 	var result string
 	if err := xml.Unmarshal(xmlRes, &result); err != nil {
 		return "", err
@@ -109,3 +143,8 @@ func performIBANBICRequest(action string, value string) (string, error) {
 	return result, nil
 
 }
+
+// isIBANBICException returns true when the body is an exception of the IBANBIC service
+func isIBANBICException(body []byte) bool {
+	return strings.Contains(string(body), "Exception")
+}
@@ -1,6 +1,7 @@
 package finance_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
 )
 
 func TestCheckIBAN(t *testing.T) {
@@ -31,6 +33,14 @@ func TestCheckIBAN(t *testing.T) {
 		{"", "", "", "", true},
 	}
 
+	s := financetest.NewIBANBICServer()
+	defer s.Close()
+
+	finance.IBANBICServiceURL = s.URL
+	defer func() {
+		finance.IBANBICServiceURL = finance.DefaultIBANBICServiceURL
+	}()
+
 	for _, tc := range tests {
 		t.Run(tc.number, func(t *testing.T) {
 
@@ -164,3 +174,31 @@ func TestCheckIBANPartialFail(t *testing.T) {
 	assert.Error(t, err, "error")
 
 }
+
+func TestCheckIBANContextCancelled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var secondCall bool
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.RequestURI, "BBANtoIBANandBIC") {
+				secondCall = true
+			}
+			cancel()
+			w.Write([]byte(`<string xmlns="http://tempuri.org/">KBC Bank</string>`))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithIBANBICServiceURL(s.URL))
+
+	result, err := client.CheckIBANContext(ctx, "738120256174")
+
+	assert.Nil(t, result, "result")
+	assert.Equal(t, context.Canceled, err, "error")
+	assert.False(t, secondCall, "second-call")
+
+}
@@ -0,0 +1,96 @@
+package finance_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestValidateIBAN(t *testing.T) {
+
+	type test struct {
+		iban          string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"BE68539007547034", nil},
+		{"BE16 7381 2025 6174", nil},
+		{"be16 7381 2025 6174", nil},
+		{"DE89370400440532013000", nil},
+		{"GB29NWBK60161331926819", nil},
+		{"FR1420041010050500013M02606", nil},
+		{"NL91ABNA0417164300", nil},
+		{"ES9121000418450200051332", nil},
+		{"IT60X0542811101000000123456", nil},
+		{"CH9300762011623852957", nil},
+		{"AT611904300234573201", nil},
+		{"PL61109010140000071219812874", nil},
+		{"NO9386011117947", nil},
+		{"SE4550000000058398257466", nil},
+		{"DK5000400440116243", nil},
+		{"FI2112345600000785", nil},
+		{"LU280019400644750000", nil},
+		{"IE29AIBK93115212345678", nil},
+		{"PT50000201231234567890154", nil},
+		{"MT84MALT011000012345MTLCAST001S", nil},
+		{"", finance.ErrIBANTooShort},
+		{"BE1", finance.ErrIBANTooShort},
+		{"BE68-5390-0754-7034", finance.ErrIBANInvalidCharacters},
+		{"XX68539007547034", finance.ErrIBANUnknownCountry},
+		{"BE6853900754703", finance.ErrIBANInvalidLength},
+		{"DE8937040044053201300", finance.ErrIBANInvalidLength},
+		{"BEAB539007547034", finance.ErrIBANInvalidFormat},
+		{"BE685390075470AB", finance.ErrIBANInvalidFormat},
+		{"NL91ABN10417164300", finance.ErrIBANInvalidFormat},
+		{"BE69539007547034", finance.ErrIBANInvalidChecksum},
+		{"GB29NWBK60161331926818", finance.ErrIBANInvalidChecksum},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.iban, func(t *testing.T) {
+
+			err := finance.ValidateIBAN(tc.iban)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err, "error")
+				return
+			}
+
+			assert.True(t, errors.Is(err, tc.expectedError), "expected %v, got %v", tc.expectedError, err)
+
+			var ibanErr *finance.IBANError
+			if assert.True(t, errors.As(err, &ibanErr), "iban-error") {
+				assert.Equal(t, tc.expectedError, ibanErr.Rule, "rule")
+				assert.Equal(t, finance.NormalizeIBAN(tc.iban), ibanErr.IBAN, "iban")
+			}
+
+		})
+	}
+
+}
+
+func TestFormatIBAN(t *testing.T) {
+
+	type test struct {
+		iban     string
+		expected string
+	}
+
+	var tests = []test{
+		{"BE16738120256174", "BE16 7381 2025 6174"},
+		{"be16 7381 2025 6174", "BE16 7381 2025 6174"},
+		{"FR1420041010050500013M02606", "FR14 2004 1010 0505 0001 3M02 606"},
+		{"", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.iban, func(t *testing.T) {
+			assert.Equal(t, tc.expected, finance.FormatIBAN(tc.iban))
+		})
+	}
+
+}
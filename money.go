@@ -0,0 +1,231 @@
+package finance
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrCurrencyMismatch is the error returned when combining amounts in different currencies
+	ErrCurrencyMismatch = errors.New("Currencies don't match")
+
+	// ErrInvalidRatios is the error returned when an amount can't be allocated across the given ratios
+	ErrInvalidRatios = errors.New("Ratios should be positive and not all zero")
+
+	// ErrNoRates is the error returned when converting an amount without an exchange rate table
+	ErrNoRates = errors.New("No exchange rates available")
+)
+
+// Money is an amount of money in a given currency
+//
+// The amount is stored as an integer number of minor units (e.g. cents) so that adding and splitting amounts
+// never introduces rounding errors.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// NewMoney returns an amount of money expressed in minor units, e.g. NewMoney(1050, "EUR") is 10.50 EUR
+func NewMoney(amount int64, currency string) Money {
+	return Money{
+		amount:   amount,
+		currency: normalizeCurrencyCode(currency),
+	}
+}
+
+// MoneyFromFloat returns the amount of money closest to value, which is expressed in major units
+func MoneyFromFloat(value float64, currency string) Money {
+	currency = normalizeCurrencyCode(currency)
+	r := new(big.Rat).SetFloat64(value)
+	if r == nil {
+		r = new(big.Rat)
+	}
+	r.Mul(r, pow10Rat(minorUnits(currency)))
+	return Money{amount: roundRat(r), currency: currency}
+}
+
+// Amount returns the amount in minor units
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency returns the ISO 4217 code of the currency
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Float64 returns the amount in major units
+func (m Money) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(big.NewInt(m.amount), pow10Int(minorUnits(m.currency))).Float64()
+	return f
+}
+
+// IsZero returns true when the amount is zero
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// String returns the amount in major units followed by the currency, e.g. "10.50 EUR"
+func (m Money) String() string {
+
+	digits := minorUnits(m.currency)
+
+	sign := ""
+	amount := m.amount
+	if amount < 0 {
+		sign = "-"
+	}
+
+	value := strconv.FormatUint(absInt64(amount), 10)
+	if digits > 0 {
+		value = leftPad(value, digits+1, '0')
+		value = value[:len(value)-digits] + "." + value[len(value)-digits:]
+	}
+
+	return sign + value + " " + m.currency
+
+}
+
+// Add returns the sum of both amounts
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, errors.Wrap(ErrCurrencyMismatch, m.currency+" and "+other.currency)
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+// Subtract returns the difference between both amounts
+func (m Money) Subtract(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, errors.Wrap(ErrCurrencyMismatch, m.currency+" and "+other.currency)
+	}
+	return Money{amount: m.amount - other.amount, currency: m.currency}, nil
+}
+
+// Multiply returns the amount multiplied by factor, rounded half away from zero to the nearest minor unit
+func (m Money) Multiply(factor float64) Money {
+	r := new(big.Rat).SetFloat64(factor)
+	if r == nil {
+		r = new(big.Rat)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(m.amount))
+	return Money{amount: roundRat(r), currency: m.currency}
+}
+
+// Allocate splits the amount across the given ratios without losing any minor units
+//
+// The minor units which remain after dividing are handed out one by one, starting with the first share.
+// For example, allocating 0.05 EUR across the ratios 3 and 7 gives 0.02 EUR and 0.03 EUR.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+
+	total := 0
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, ErrInvalidRatios
+		}
+		total += ratio
+	}
+	if total == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	var sign int64 = 1
+	amount := m.amount
+	if amount < 0 {
+		sign = -1
+		amount = -amount
+	}
+
+	shares := make([]Money, len(ratios))
+	remainder := amount
+	for i, ratio := range ratios {
+		share := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(ratio)))
+		share.Quo(share, big.NewInt(int64(total)))
+		shares[i] = Money{amount: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+
+	for i := 0; remainder > 0; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].amount++
+		remainder--
+	}
+
+	for i := range shares {
+		shares[i].amount *= sign
+	}
+
+	return shares, nil
+
+}
+
+// ConvertTo converts the amount to another currency using the given exchange rates
+//
+// The rates are used exactly as they were published, like ConvertDecimal does. The result is rounded half away from
+// zero to the minor unit of the target currency. ErrNoRates is returned when rates is nil.
+func (m Money) ConvertTo(currency string, rates *ExchangeRateTable) (Money, error) {
+
+	if rates == nil {
+		return Money{}, ErrNoRates
+	}
+
+	_, fromRate, err := rates.decimalRate(m.currency)
+	if err != nil {
+		return Money{}, errors.Wrap(err, "Invalid from currency: "+m.currency)
+	}
+
+	toCode, toRate, err := rates.decimalRate(currency)
+	if err != nil {
+		return Money{}, errors.Wrap(err, "Invalid to currency: "+currency)
+	}
+
+	r := new(big.Rat).SetInt64(m.amount)
+	r.Mul(r, toRate)
+	r.Quo(r, fromRate)
+	r.Mul(r, pow10Rat(minorUnits(toCode)))
+	r.Quo(r, pow10Rat(minorUnits(m.currency)))
+
+	return Money{amount: roundRat(r), currency: toCode}, nil
+
+}
+
+// minorUnits returns the number of decimals of the minor unit of a currency
+//
+// Unknown currencies use 2 decimals, currencies without a minor unit (e.g. gold) use none.
+func minorUnits(currency string) int {
+	c, ok := currencyRegistry[currency]
+	if !ok {
+		return 2
+	}
+	if c.MinorUnits < 0 {
+		return 0
+	}
+	return c.MinorUnits
+}
+
+// roundRat rounds a rational number half away from zero to an integer
+func roundRat(r *big.Rat) int64 {
+	return roundRational(r, RoundHalfUp).Int64()
+}
+
+// pow10Int returns 10 to the power of n
+func pow10Int(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// pow10Rat returns 10 to the power of n as a rational number
+func pow10Rat(n int) *big.Rat {
+	return new(big.Rat).SetInt(pow10Int(n))
+}
+
+// absInt64 returns the absolute value of n
+func absInt64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-n)
+	}
+	return uint64(n)
+}
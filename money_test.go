@@ -0,0 +1,218 @@
+package finance_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestMoneyString(t *testing.T) {
+
+	type test struct {
+		name     string
+		money    finance.Money
+		expected string
+	}
+
+	var tests = []test{
+		{"eur", finance.NewMoney(1050, "EUR"), "10.50 EUR"},
+		{"eur-lowercase", finance.NewMoney(1050, "eur"), "10.50 EUR"},
+		{"eur-cents", finance.NewMoney(5, "EUR"), "0.05 EUR"},
+		{"eur-negative", finance.NewMoney(-1999, "EUR"), "-19.99 EUR"},
+		{"jpy", finance.NewMoney(1500, "JPY"), "1500 JPY"},
+		{"kwd", finance.NewMoney(1500, "KWD"), "1.500 KWD"},
+		{"from-float", finance.MoneyFromFloat(19.99, "USD"), "19.99 USD"},
+		{"from-float-rounded", finance.MoneyFromFloat(0.125, "EUR"), "0.13 EUR"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.money.String(), "string")
+		})
+	}
+
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+
+	a := finance.NewMoney(1010, "EUR")
+	b := finance.NewMoney(2020, "EUR")
+
+	sum, err := a.Add(b)
+	assert.NoError(t, err, "add-error")
+	assert.EqualValues(t, 3030, sum.Amount(), "add")
+
+	diff, err := a.Subtract(b)
+	assert.NoError(t, err, "subtract-error")
+	assert.EqualValues(t, -1010, diff.Amount(), "subtract")
+
+	assert.EqualValues(t, 1222, a.Multiply(1.21).Amount(), "multiply")
+	assert.EqualValues(t, -1222, a.Multiply(-1.21).Amount(), "multiply-negative")
+	assert.Equal(t, 10.1, a.Float64(), "float")
+
+	_, err = a.Add(finance.NewMoney(100, "USD"))
+	assert.True(t, errors.Is(err, finance.ErrCurrencyMismatch), "add-mismatch")
+
+	_, err = a.Subtract(finance.NewMoney(100, "USD"))
+	assert.True(t, errors.Is(err, finance.ErrCurrencyMismatch), "subtract-mismatch")
+
+}
+
+func TestMoneyAllocate(t *testing.T) {
+
+	type test struct {
+		name          string
+		money         finance.Money
+		ratios        []int
+		expected      []int64
+		expectedError error
+	}
+
+	var tests = []test{
+		{"even", finance.NewMoney(100, "EUR"), []int{1, 1}, []int64{50, 50}, nil},
+		{"thirds", finance.NewMoney(100, "EUR"), []int{1, 1, 1}, []int64{34, 33, 33}, nil},
+		{"ratios", finance.NewMoney(5, "EUR"), []int{3, 7}, []int64{2, 3}, nil},
+		{"zero-ratio", finance.NewMoney(100, "EUR"), []int{0, 1, 1}, []int64{0, 50, 50}, nil},
+		{"zero-ratio-remainder", finance.NewMoney(101, "EUR"), []int{0, 1, 1}, []int64{0, 51, 50}, nil},
+		{"negative", finance.NewMoney(-100, "EUR"), []int{1, 1, 1}, []int64{-34, -33, -33}, nil},
+		{"no-ratios", finance.NewMoney(100, "EUR"), []int{}, nil, finance.ErrInvalidRatios},
+		{"all-zero", finance.NewMoney(100, "EUR"), []int{0, 0}, nil, finance.ErrInvalidRatios},
+		{"negative-ratio", finance.NewMoney(100, "EUR"), []int{2, -1}, nil, finance.ErrInvalidRatios},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			shares, err := tc.money.Allocate(tc.ratios...)
+
+			if tc.expectedError != nil {
+				assert.Equal(t, tc.expectedError, err, "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+
+			actual := make([]int64, len(shares))
+			for i, share := range shares {
+				actual[i] = share.Amount()
+				assert.Equal(t, tc.money.Currency(), share.Currency(), "currency")
+			}
+			assert.Equal(t, tc.expected, actual, "shares")
+
+		})
+	}
+
+}
+
+func TestMoneyConvertTo(t *testing.T) {
+
+	rates := &finance.ExchangeRateTable{
+		Date:  time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		Base:  finance.BaseCurrency,
+		Rates: map[string]float64{"EUR": 1, "USD": 1.0919, "JPY": 155.52, "KWD": 0.3356},
+	}
+
+	type test struct {
+		name          string
+		money         finance.Money
+		to            string
+		expected      string
+		expectedError bool
+	}
+
+	var tests = []test{
+		{"eur-usd", finance.NewMoney(10000, "EUR"), "USD", "109.19 USD", false},
+		{"eur-jpy", finance.NewMoney(1050, "EUR"), "jpy", "1633 JPY", false},
+		{"jpy-eur", finance.NewMoney(1000, "JPY"), "EUR", "6.43 EUR", false},
+		{"usd-kwd", finance.NewMoney(10000, "USD"), "KWD", "30.735 KWD", false},
+		{"eur-eur", finance.NewMoney(1050, "EUR"), "EUR", "10.50 EUR", false},
+		{"invalid-from", finance.NewMoney(100, "XXX"), "EUR", "", true},
+		{"invalid-to", finance.NewMoney(100, "EUR"), "XXX", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			actual, err := tc.money.ConvertTo(tc.to, rates)
+
+			if tc.expectedError {
+				assert.Error(t, err, "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			assert.Equal(t, tc.expected, actual.String(), "result")
+
+		})
+	}
+
+}
+
+func TestMoneyConvertToMatchesConvertDecimal(t *testing.T) {
+
+	type test struct {
+		name     string
+		money    finance.Money
+		to       string
+		rate     string
+		expected string
+	}
+
+	var tests = []test{
+		{"usd", finance.NewMoney(1250, "EUR"), "USD", "1.0876", "13.60 USD"},
+		{"gbp", finance.NewMoney(25000, "EUR"), "GBP", "0.86518", "216.30 GBP"},
+		{"chf", finance.NewMoney(1000, "EUR"), "CHF", "0.8765", "8.77 CHF"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			rate, _ := strconv.ParseFloat(tc.rate, 64)
+			rates := &finance.ExchangeRateTable{
+				Base:         finance.BaseCurrency,
+				Rates:        map[string]float64{"EUR": 1, tc.to: rate},
+				DecimalRates: map[string]string{"EUR": "1", tc.to: tc.rate},
+			}
+
+			actual, err := tc.money.ConvertTo(tc.to, rates)
+			assert.NoError(t, err, "error")
+			assert.Equal(t, tc.expected, actual.String(), "result")
+
+			conversion, err := rates.ConvertDecimal(strconv.FormatFloat(tc.money.Float64(), 'f', 2, 64), "EUR", tc.to, finance.DecimalOptions{Precision: 2, Rounding: finance.RoundHalfUp})
+			if assert.NoError(t, err, "decimal-error") {
+				assert.Equal(t, tc.expected, conversion.Result+" "+tc.to, "decimal-result")
+			}
+
+		})
+	}
+
+}
+
+func TestMoneyConvertToZeroRate(t *testing.T) {
+
+	rates := &finance.ExchangeRateTable{
+		Base:  finance.BaseCurrency,
+		Rates: map[string]float64{"EUR": 1, "USD": 0},
+	}
+
+	_, err := finance.NewMoney(100, "USD").ConvertTo("EUR", rates)
+	assert.True(t, errors.Is(err, finance.ErrInvalidRatesDocument), "zero-from")
+
+	_, err = finance.NewMoney(100, "EUR").ConvertTo("USD", rates)
+	assert.True(t, errors.Is(err, finance.ErrInvalidRatesDocument), "zero-to")
+
+}
+
+func TestMoneyConvertToWithoutRates(t *testing.T) {
+
+	actual, err := finance.NewMoney(100, "EUR").ConvertTo("USD", nil)
+
+	assert.True(t, errors.Is(err, finance.ErrNoRates), "error")
+	assert.True(t, actual.IsZero(), "result")
+
+}
@@ -0,0 +1,122 @@
+package finance
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrOGMInvalidFormat is the error returned when a text doesn't contain a structured communication
+	ErrOGMInvalidFormat = errors.New("Structured communication should contain 12 digits")
+
+	// ErrOGMInvalidChecksum is the error returned when the check digits of a structured communication are wrong
+	ErrOGMInvalidChecksum = errors.New("Structured communication has invalid check digits")
+
+	// ErrOGMInvalidReference is the error returned when a reference can't be turned into a structured communication
+	ErrOGMInvalidReference = errors.New("Reference should contain 1 to 10 digits")
+)
+
+// ogmDigits matches the digits of a structured communication with optional slashes and delimiters
+const ogmDigits = `(?:(?:\+{3}|\*{3})\s*)?(\d{3})\s*/?\s*(\d{4})\s*/?\s*(\d{5})(?:\s*(?:\+{3}|\*{3}))?`
+
+var (
+	// ogmPattern matches a structured communication in free text
+	ogmPattern = regexp.MustCompile(`(?:^|[^\d/])` + ogmDigits + `(?:$|[^\d/])`)
+
+	// ogmExactPattern matches a text which contains nothing but a structured communication
+	ogmExactPattern = regexp.MustCompile(`^\s*` + ogmDigits + `\s*$`)
+)
+
+// GenerateOGM creates the Belgian structured communication (OGM/VCS) for a reference such as an invoice number
+//
+// The reference may contain up to 10 digits and is padded with zeros on the left. Spaces, dots, dashes and slashes
+// are ignored, e.g. 2024/0042. The communication is returned in its print format, e.g. +++002/0240/04222+++.
+func GenerateOGM(reference string) (string, error) {
+
+	digits := strings.NewReplacer(" ", "", ".", "", "-", "", "/", "").Replace(strings.TrimSpace(reference))
+	if len(digits) == 0 || len(digits) > 10 || !isDigits(digits) {
+		return "", errors.Wrap(ErrOGMInvalidReference, reference)
+	}
+
+	base := leftPad(digits, 10, '0')
+	value, _ := strconv.ParseInt(base, 10, 64)
+
+	return FormatOGM(base + leftPad(strconv.FormatInt(belgianCheckDigits(value), 10), 2, '0')), nil
+
+}
+
+// ParseOGM finds the structured communication in a text and returns its 12 digits
+//
+// It accepts the forms banks deliver in their statements, such as +++090/9337/55493+++, ***090/9337/55493***,
+// 090/9337/55493 and 090933755493, also when they are surrounded by other text. When the text contains multiple
+// candidates, the first one with valid check digits is returned.
+func ParseOGM(text string) (string, error) {
+
+	matches := ogmPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return "", errors.Wrap(ErrOGMInvalidFormat, text)
+	}
+
+	for _, match := range matches {
+		digits := match[1] + match[2] + match[3]
+		if checkOGM(digits) {
+			return digits, nil
+		}
+	}
+
+	return "", errors.Wrap(ErrOGMInvalidChecksum, text)
+
+}
+
+// ValidateOGM checks the format and the mod-97 check digits of a structured communication
+//
+// The same forms as ParseOGM are accepted, but the text should contain nothing else.
+func ValidateOGM(communication string) error {
+
+	match := ogmExactPattern.FindStringSubmatch(communication)
+	if match == nil {
+		return errors.Wrap(ErrOGMInvalidFormat, communication)
+	}
+
+	if !checkOGM(match[1] + match[2] + match[3]) {
+		return errors.Wrap(ErrOGMInvalidChecksum, communication)
+	}
+
+	return nil
+
+}
+
+// FormatOGM converts a structured communication to its print format, e.g. +++090/9337/55493+++
+//
+// The communication is returned unchanged when it doesn't consist of 12 digits.
+func FormatOGM(communication string) string {
+
+	digits := strings.Trim(strings.NewReplacer(" ", "", "/", "").Replace(communication), "+*")
+	if len(digits) != 12 || !isDigits(digits) {
+		return communication
+	}
+
+	return "+++" + digits[0:3] + "/" + digits[3:7] + "/" + digits[7:12] + "+++"
+
+}
+
+// checkOGM checks the check digits of the 12 digits of a structured communication
+func checkOGM(digits string) bool {
+	base, _ := strconv.ParseInt(digits[0:10], 10, 64)
+	check, _ := strconv.ParseInt(digits[10:12], 10, 64)
+	return check == belgianCheckDigits(base)
+}
+
+// belgianCheckDigits computes the mod-97 check digits used by Belgian account numbers and structured communications
+//
+// A remainder of zero is replaced by 97.
+func belgianCheckDigits(base int64) int64 {
+	check := base % 97
+	if check == 0 {
+		return 97
+	}
+	return check
+}
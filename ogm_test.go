@@ -0,0 +1,134 @@
+package finance_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestGenerateOGM(t *testing.T) {
+
+	type test struct {
+		reference     string
+		expected      string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"2024/0042", "+++002/0240/04222+++", nil},
+		{"0909337554", "+++090/9337/55493+++", nil},
+		{"97", "+++000/0000/09797+++", nil},
+		{"0", "+++000/0000/00097+++", nil},
+		{"9999999999", "+++999/9999/99948+++", nil},
+		{"", "", finance.ErrOGMInvalidReference},
+		{"12345678901", "", finance.ErrOGMInvalidReference},
+		{"INV-42", "", finance.ErrOGMInvalidReference},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.reference, func(t *testing.T) {
+
+			actual, err := finance.GenerateOGM(tc.reference)
+
+			if tc.expectedError != nil {
+				assert.Empty(t, actual, "actual")
+				assert.True(t, errors.Is(err, tc.expectedError), "error")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			assert.Equal(t, tc.expected, actual, "actual")
+			assert.NoError(t, finance.ValidateOGM(actual), "valid")
+
+		})
+	}
+
+}
+
+func TestParseOGM(t *testing.T) {
+
+	type test struct {
+		name          string
+		text          string
+		expected      string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"plus", "+++090/9337/55493+++", "090933755493", nil},
+		{"stars", "***090/9337/55493***", "090933755493", nil},
+		{"no-delimiters", "090/9337/55493", "090933755493", nil},
+		{"digits", "090933755493", "090933755493", nil},
+		{"spaces", "+++ 090 / 9337 / 55493 +++", "090933755493", nil},
+		{"free-text", "Betaling factuur +++090/9337/55493+++ dank u", "090933755493", nil},
+		{"multiple", "ref 090/9337/55494 en 090/9337/55493", "090933755493", nil},
+		{"invalid-checksum", "+++090/9337/55494+++", "", finance.ErrOGMInvalidChecksum},
+		{"too-many-digits", "1090933755493", "", finance.ErrOGMInvalidFormat},
+		{"too-few-digits", "+++090/9337/5549+++", "", finance.ErrOGMInvalidFormat},
+		{"empty", "", "", finance.ErrOGMInvalidFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			actual, err := finance.ParseOGM(tc.text)
+
+			if tc.expectedError != nil {
+				assert.Empty(t, actual, "actual")
+				assert.True(t, errors.Is(err, tc.expectedError), "expected %v, got %v", tc.expectedError, err)
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			assert.Equal(t, tc.expected, actual, "actual")
+
+		})
+	}
+
+}
+
+func TestValidateOGM(t *testing.T) {
+
+	type test struct {
+		communication string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"+++090/9337/55493+++", nil},
+		{"***090/9337/55493***", nil},
+		{" 090933755493 ", nil},
+		{"+++090/9337/55494+++", finance.ErrOGMInvalidChecksum},
+		{"Factuur +++090/9337/55493+++", finance.ErrOGMInvalidFormat},
+		{"+++090/9337/5549A+++", finance.ErrOGMInvalidFormat},
+		{"", finance.ErrOGMInvalidFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.communication, func(t *testing.T) {
+
+			err := finance.ValidateOGM(tc.communication)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err, "error")
+				return
+			}
+
+			assert.True(t, errors.Is(err, tc.expectedError), "expected %v, got %v", tc.expectedError, err)
+
+		})
+	}
+
+}
+
+func TestFormatOGM(t *testing.T) {
+
+	assert.Equal(t, "+++090/9337/55493+++", finance.FormatOGM("090933755493"), "digits")
+	assert.Equal(t, "+++090/9337/55493+++", finance.FormatOGM("***090/9337/55493***"), "stars")
+	assert.Equal(t, "+++090/9337/55493+++", finance.FormatOGM("090 / 9337 / 55493"), "spaces")
+	assert.Equal(t, "12345", finance.FormatOGM("12345"), "invalid")
+
+}
@@ -0,0 +1,101 @@
+package finance
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy defines how requests which fail with a transient error are retried
+//
+// Requests are retried when the service can't be reached, when it returns an HTTP 5xx status and when VIES
+// reports that it's too busy (MS_MAX_CONCURRENT_REQ, GLOBAL_MAX_CONCURRENT_REQ or SERVICE_UNAVAILABLE). The delay
+// before each retry grows exponentially starting from Delay. A zero policy doesn't retry at all.
+type RetryPolicy struct {
+	MaxAttempts int           // The maximum number of attempts, including the first one
+	Delay       time.Duration // The delay before the first retry
+	MaxDelay    time.Duration // The maximum delay between two attempts, zero means no maximum
+	Multiplier  float64       // The factor by which the delay grows after each retry, values up to 1 keep it constant
+	Jitter      float64       // The fraction of the delay which is randomized, between 0 and 1
+	MaxElapsed  time.Duration // The total time budget for all attempts, zero means no budget
+}
+
+// DefaultRetryPolicy is a sensible retry policy for the ECB, VIES and IBANBIC services
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	Delay:       500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Multiplier:  2,
+	Jitter:      0.2,
+	MaxElapsed:  30 * time.Second,
+}
+
+// viesBusyFaults are the VIES faults after which the request is retried
+var viesBusyFaults = map[string]bool{
+	VIESFaultServiceUnavailable:         true,
+	VIESFaultGlobalMaxConcurrentReq:     true,
+	VIESFaultGlobalMaxConcurrentReqTime: true,
+	VIESFaultMSMaxConcurrentReq:         true,
+	VIESFaultMSMaxConcurrentReqTime:     true,
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// nextDelay returns the delay before the next attempt, or false when no more attempts should be made
+func (p RetryPolicy) nextDelay(attempt int, elapsed time.Duration) (time.Duration, bool) {
+
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	delay := float64(p.Delay)
+	if p.Multiplier > 1 {
+		delay *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		jitterMu.Lock()
+		delay += delay * p.Jitter * (2*jitterRand.Float64() - 1)
+		jitterMu.Unlock()
+	}
+
+	result := time.Duration(delay)
+	if p.MaxElapsed > 0 && elapsed+result >= p.MaxElapsed {
+		return 0, false
+	}
+
+	return result, true
+
+}
+
+// retryOnServerError retries responses with an HTTP 5xx status
+func retryOnServerError(status int, _ []byte) bool {
+	return status >= 500
+}
+
+// retryIBANBICResponse retries IBANBIC responses with an HTTP 5xx status, except for the exceptions of the service
+//
+// The service reports invalid input with an exception and an HTTP 500 status, retrying gives the same answer.
+func retryIBANBICResponse(status int, body []byte) bool {
+	if isIBANBICException(body) {
+		return false
+	}
+	return status >= 500
+}
+
+// retryVIESResponse retries VIES responses which indicate that VIES or the member state is too busy
+//
+// VIES returns its faults with an HTTP 500 status, so only server errors without a fault are retried as well.
+func retryVIESResponse(status int, body []byte) bool {
+	if fault := parseVIESFault(body); fault != "" {
+		return viesBusyFaults[newVIESError(fault).Code]
+	}
+	return status >= 500
+}
@@ -0,0 +1,294 @@
+package finance_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+const checkVatResponse = `<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body><ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types"><ns2:countryCode>BE</ns2:countryCode><ns2:vatNumber>0836157420</ns2:vatNumber><ns2:requestDate>2024-01-03+01:00</ns2:requestDate><ns2:valid>true</ns2:valid><ns2:name>SRL APPLE RETAIL BELGIUM</ns2:name><ns2:address>Avenue du Port 86C/204
+1000 Bruxelles</ns2:address></ns2:checkVatResponse></env:Body></env:Envelope>`
+
+var fastRetryPolicy = finance.RetryPolicy{
+	MaxAttempts: 3,
+	Delay:       5 * time.Millisecond,
+	Multiplier:  2,
+	Jitter:      0.5,
+}
+
+func TestRetryVIESBusy(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				writeVIESFault(w, finance.VIESFaultMSMaxConcurrentReq)
+				return
+			}
+			w.Write([]byte(checkVatResponse))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithRetryPolicy(fastRetryPolicy),
+	)
+
+	result, err := client.CheckVAT("BE0836157420")
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, result, "result") {
+		assert.True(t, result.IsValid, "is-valid")
+		assert.Equal(t, nameApple, result.Name, "name")
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestRetryVIESExhausted(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			writeVIESFault(w, finance.VIESFaultServiceUnavailable)
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithRetryPolicy(fastRetryPolicy),
+	)
+
+	result, err := client.CheckVAT("BE0836157420")
+
+	assert.Nil(t, result, "result")
+	assert.True(t, errors.Is(err, finance.ErrVIESServiceUnavailable), "error")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestRetryVIESNotRetryable(t *testing.T) {
+
+	type test struct {
+		fault string
+	}
+
+	var tests = []test{
+		{finance.VIESFaultInvalidInput},
+		{finance.VIESFaultMSUnavailable},
+		{finance.VIESFaultTimeout},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fault, func(t *testing.T) {
+
+			var attempts int32
+
+			s := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					atomic.AddInt32(&attempts, 1)
+					writeVIESFault(w, tc.fault)
+				}),
+			)
+			defer s.Close()
+
+			client := finance.NewClient(
+				finance.WithVATServiceURL(s.URL),
+				finance.WithRetryPolicy(fastRetryPolicy),
+			)
+
+			_, err := client.CheckVAT("BE0836157420")
+
+			assert.True(t, errors.Is(err, &finance.VIESError{Code: tc.fault}), "error")
+			assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "attempts")
+
+		})
+	}
+
+}
+
+func TestRetryIBANBICServerError(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if strings.Contains(r.RequestURI, "BBANtoIBANandBIC") {
+				w.Write([]byte(`<string xmlns="http://tempuri.org/">BE16 7381 2025 6174#KRED BE BB</string>`))
+				return
+			}
+			w.Write([]byte(`<string xmlns="http://tempuri.org/">KBC Bank</string>`))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithIBANBICServiceURL(s.URL),
+		finance.WithRetryPolicy(fastRetryPolicy),
+	)
+
+	result, err := client.CheckIBAN("738120256174")
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, result, "result") {
+		assert.Equal(t, "KBC Bank", result.BankName, "bank-name")
+		assert.Equal(t, "BE16 7381 2025 6174", result.IBAN, "IBAN")
+		assert.Equal(t, "KRED BE BB", result.BIC, "BIC")
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestRetryRatesConnectionError(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			w.Write([]byte(dailyRatesXML))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithRetryPolicy(fastRetryPolicy),
+	)
+
+	rates, err := client.ExchangeRates()
+
+	assert.NoError(t, err, "error")
+	assert.NotEmpty(t, rates, "rates")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestRetryMaxElapsed(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithRetryPolicy(finance.RetryPolicy{
+			MaxAttempts: 10,
+			Delay:       40 * time.Millisecond,
+			MaxElapsed:  100 * time.Millisecond,
+		}),
+	)
+
+	start := time.Now()
+	_, err := client.ExchangeRates()
+
+	assert.Error(t, err, "error")
+	assert.True(t, time.Since(start) < 200*time.Millisecond, "elapsed")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestRetryServerErrorExhausted(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("<html><body><h1>Service Unavailable</h1></body></html>"))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithRatesURL(s.URL),
+		finance.WithVATServiceURL(s.URL),
+		finance.WithIBANBICServiceURL(s.URL),
+		finance.WithRetryPolicy(fastRetryPolicy),
+	)
+
+	table, err := client.LatestExchangeRates()
+
+	assert.Nil(t, table, "rates")
+	var statusErr *finance.StatusError
+	if assert.True(t, errors.As(err, &statusErr), "rates-error") {
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode, "rates-status")
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "rates-attempts")
+
+	atomic.StoreInt32(&attempts, 0)
+	info, err := client.CheckIBAN("738120256174")
+
+	assert.Nil(t, info, "iban")
+	assert.Equal(t, finance.ErrIBANBICServiceUnreachable, err, "iban-error")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "iban-attempts")
+
+	atomic.StoreInt32(&attempts, 0)
+	vat, err := client.CheckVAT("BE0836157420")
+
+	assert.Nil(t, vat, "vat")
+	assert.Equal(t, finance.ErrVATserviceUnreachable, err, "vat-error")
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts), "vat-attempts")
+
+}
+
+func TestIBANBICExceptionWithServerError(t *testing.T) {
+
+	var attempts int32
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("System.ArgumentException: Invalid BBAN\n   at IBANBIC.BBANtoBANKNAME(String Value)"))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithIBANBICServiceURL(s.URL),
+		finance.WithRetryPolicy(fastRetryPolicy),
+	)
+
+	info, err := client.CheckIBAN("738120256174")
+
+	assert.Nil(t, info, "info")
+	assert.EqualError(t, err, finance.ErrIBANBICServiceError+"System.ArgumentException: Invalid BBAN", "error")
+	assert.True(t, errors.Is(err, finance.ErrIBANBICInvalidInput), "invalid-input")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func writeVIESFault(w http.ResponseWriter, fault string) {
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>` + fault + `</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+}
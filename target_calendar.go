@@ -0,0 +1,88 @@
+package finance
+
+import (
+	"time"
+)
+
+// IsTARGETBusinessDay returns true when the TARGET payment system is open on the given day
+//
+// TARGET is closed on weekends, New Year's Day, Good Friday, Easter Monday, 1 May, Christmas Day and 26 December.
+// The ECB doesn't publish reference rates on those days. Only the calendar date of t is used, not its time.
+func IsTARGETBusinessDay(t time.Time) bool {
+
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+
+	switch {
+	case t.Month() == time.January && t.Day() == 1:
+		return false
+	case t.Month() == time.May && t.Day() == 1:
+		return false
+	case t.Month() == time.December && (t.Day() == 25 || t.Day() == 26):
+		return false
+	}
+
+	easter := easterSunday(t.Year())
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Equal(easter.AddDate(0, 0, -2)) || day.Equal(easter.AddDate(0, 0, 1)) {
+		return false
+	}
+
+	return true
+
+}
+
+// PreviousTARGETBusinessDay returns the last TARGET business day before the given day
+func PreviousTARGETBusinessDay(t time.Time) time.Time {
+	t = t.AddDate(0, 0, -1)
+	for !IsTARGETBusinessDay(t) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// NextTARGETBusinessDay returns the first TARGET business day after the given day
+func NextTARGETBusinessDay(t time.Time) time.Time {
+	t = t.AddDate(0, 0, 1)
+	for !IsTARGETBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// TARGETHolidays returns the TARGET closing days of a year which don't fall in a weekend, sorted ascending
+func TARGETHolidays(year int) []time.Time {
+
+	var holidays []time.Time
+
+	for day := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() == year; day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday && !IsTARGETBusinessDay(day) {
+			holidays = append(holidays, day)
+		}
+	}
+
+	return holidays
+
+}
+
+// easterSunday returns the date of Easter Sunday in the Gregorian calendar
+//
+// It uses the anonymous Gregorian algorithm (Meeus/Jones/Butcher).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
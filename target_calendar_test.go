@@ -0,0 +1,58 @@
+package finance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestIsTARGETBusinessDay(t *testing.T) {
+
+	type test struct {
+		name     string
+		date     time.Time
+		expected bool
+	}
+
+	var tests = []test{
+		{"wednesday", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), true},
+		{"saturday", time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), false},
+		{"sunday", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), false},
+		{"new-year", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"good-friday-2024", time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC), false},
+		{"easter-monday-2024", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), false},
+		{"good-friday-2025", time.Date(2025, 4, 18, 0, 0, 0, 0, time.UTC), false},
+		{"easter-monday-2025", time.Date(2025, 4, 21, 0, 0, 0, 0, time.UTC), false},
+		{"labour-day", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"christmas-eve", time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC), true},
+		{"christmas", time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), false},
+		{"boxing-day", time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), false},
+		{"new-years-eve", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{"late-evening-local", time.Date(2024, 3, 29, 23, 30, 0, 0, time.FixedZone("CET", 3600)), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, finance.IsTARGETBusinessDay(tc.date), "business-day")
+		})
+	}
+
+}
+
+func TestTARGETBusinessDayNavigation(t *testing.T) {
+
+	goodFriday := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2024-03-28", finance.PreviousTARGETBusinessDay(goodFriday).Format("2006-01-02"), "previous")
+	assert.Equal(t, "2024-04-02", finance.NextTARGETBusinessDay(goodFriday).Format("2006-01-02"), "next")
+
+	var holidays []string
+	for _, day := range finance.TARGETHolidays(2024) {
+		holidays = append(holidays, day.Format("2006-01-02"))
+	}
+	assert.Equal(t, []string{"2024-01-01", "2024-03-29", "2024-04-01", "2024-05-01", "2024-12-25", "2024-12-26"}, holidays, "holidays")
+
+}
@@ -0,0 +1,514 @@
+package finance
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrVATUnknownCountry is the error returned when the VAT number doesn't start with the code of an EU member state
+	ErrVATUnknownCountry = errors.New("VAT number has an unknown country code")
+
+	// ErrVATInvalidFormat is the error returned when the VAT number doesn't match the format of its member state
+	ErrVATInvalidFormat = errors.New("VAT number has an invalid format")
+
+	// ErrVATInvalidChecksum is the error returned when the check digits of the VAT number are wrong
+	ErrVATInvalidChecksum = errors.New("VAT number has invalid check digits")
+)
+
+// VATError is the error returned when a VAT number fails the offline validation
+//
+// Use errors.Is with one of the ErrVAT* errors to find out which rule failed. It also matches ErrVATnumberNotValid.
+type VATError struct {
+	VATNumber string // The sanitized VAT number, including the country code
+	Rule      error  // The rule which failed, one of the ErrVAT* errors
+}
+
+// vatRule contains the format and the check digit algorithm of the VAT numbers of a member state
+type vatRule struct {
+	Format   *regexp.Regexp           // The format of the number, without the country code
+	Checksum func(number string) bool // Checks the check digits, nil when the format is all we can check
+}
+
+// vatRules contains the rules of the EU member states, keyed by the country code used by VIES
+var vatRules = map[string]vatRule{
+	"AT": {regexp.MustCompile(`^U\d{8}$`), checkVATAT},
+	"BE": {regexp.MustCompile(`^[01]?\d{9}$`), checkVATBE},
+	"BG": {regexp.MustCompile(`^\d{9,10}$`), checkVATBG},
+	"CY": {regexp.MustCompile(`^[013-59]\d{7}[A-Z]$`), checkVATCY},
+	"CZ": {regexp.MustCompile(`^\d{8,10}$`), checkVATCZ},
+	"DE": {regexp.MustCompile(`^[1-9]\d{8}$`), checkVATDE},
+	"DK": {regexp.MustCompile(`^[1-9]\d{7}$`), checkVATDK},
+	"EE": {regexp.MustCompile(`^10\d{7}$`), checkVATEE},
+	"EL": {regexp.MustCompile(`^\d{9}$`), checkVATEL},
+	"ES": {regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`), checkVATES},
+	"FI": {regexp.MustCompile(`^\d{8}$`), checkVATFI},
+	"FR": {regexp.MustCompile(`^[0-9A-HJ-NP-Z]{2}\d{9}$`), checkVATFR},
+	"HR": {regexp.MustCompile(`^\d{11}$`), checkVATHR},
+	"HU": {regexp.MustCompile(`^\d{8}$`), checkVATHU},
+	"IE": {regexp.MustCompile(`^(\d{7}[A-W][A-IW]?|\d[A-Z+*]\d{5}[A-W])$`), checkVATIE},
+	"IT": {regexp.MustCompile(`^\d{11}$`), checkVATIT},
+	"LT": {regexp.MustCompile(`^(\d{9}|\d{12})$`), checkVATLT},
+	"LU": {regexp.MustCompile(`^\d{8}$`), checkVATLU},
+	"LV": {regexp.MustCompile(`^\d{11}$`), checkVATLV},
+	"MT": {regexp.MustCompile(`^[1-9]\d{7}$`), checkVATMT},
+	"NL": {regexp.MustCompile(`^\d{9}B\d{2}$`), checkVATNL},
+	"PL": {regexp.MustCompile(`^\d{10}$`), checkVATPL},
+	"PT": {regexp.MustCompile(`^[1-9]\d{8}$`), checkVATPT},
+	"RO": {regexp.MustCompile(`^[1-9]\d{1,9}$`), checkVATRO},
+	"SE": {regexp.MustCompile(`^\d{10}01$`), checkVATSE},
+	"SI": {regexp.MustCompile(`^[1-9]\d{7}$`), checkVATSI},
+	"SK": {regexp.MustCompile(`^[1-9]\d[2-47-9]\d{7}$`), checkVATSK},
+	"XI": {regexp.MustCompile(`^(\d{9}|\d{12}|GD[0-4]\d{2}|HA[5-9]\d{2})$`), checkVATXI},
+}
+
+// Error returns the error message
+func (e *VATError) Error() string {
+	return e.Rule.Error() + ": " + e.VATNumber
+}
+
+// Unwrap returns the rule which failed
+func (e *VATError) Unwrap() error {
+	return e.Rule
+}
+
+// Is makes the error match ErrVATnumberNotValid as well
+func (e *VATError) Is(target error) bool {
+	return target == ErrVATnumberNotValid
+}
+
+// ValidateVAT checks the format and the check digits of a VAT number without using the network
+//
+// The number should start with the country code VIES uses, e.g. EL for Greece and XI for Northern Ireland.
+// Spaces and dots are ignored. When validation fails, an *VATError is returned. A number which passes this
+// validation isn't necessarily registered, use CheckVAT for that.
+func ValidateVAT(vatNumber string) error {
+
+	vatNumber = strings.ToUpper(sanitizeVatNumber(vatNumber))
+
+	if len(vatNumber) < 3 {
+		return ErrVATNumberTooShort
+	}
+
+	rule, ok := vatRules[vatNumber[0:2]]
+	if !ok {
+		return &VATError{VATNumber: vatNumber, Rule: ErrVATUnknownCountry}
+	}
+
+	number := vatNumber[2:]
+
+	if !rule.Format.MatchString(number) {
+		return &VATError{VATNumber: vatNumber, Rule: ErrVATInvalidFormat}
+	}
+
+	if rule.Checksum != nil && !rule.Checksum(number) {
+		return &VATError{VATNumber: vatNumber, Rule: ErrVATInvalidChecksum}
+	}
+
+	return nil
+
+}
+
+// checkVATAT checks the check digit of an Austrian UID number
+func checkVATAT(number string) bool {
+	sum := 0
+	for i, d := range digitsOf(number[1:8]) {
+		if i%2 == 1 {
+			d *= 2
+		}
+		sum += d/10 + d%10
+	}
+	return (10-(sum+4)%10)%10 == digitAt(number, 8)
+}
+
+// checkVATBE checks the mod-97 check digits of a Belgian enterprise number
+func checkVATBE(number string) bool {
+	number = leftPad(number, 10, '0')
+	base, _ := strconv.Atoi(number[0:8])
+	check, _ := strconv.Atoi(number[8:10])
+	return 97-base%97 == check
+}
+
+// checkVATBG checks the check digit of a Bulgarian VAT number
+func checkVATBG(number string) bool {
+
+	digits := digitsOf(number)
+
+	if len(digits) == 9 {
+		check := weightedSum(digits[0:8], 1, 2, 3, 4, 5, 6, 7, 8) % 11
+		if check == 10 {
+			check = weightedSum(digits[0:8], 3, 4, 5, 6, 7, 8, 9, 10) % 11 % 10
+		}
+		return check == digits[8]
+	}
+
+	// Physical persons, foreigners and others each use their own algorithm
+	person := weightedSum(digits[0:9], 2, 4, 8, 5, 10, 9, 7, 3, 6) % 11 % 10
+	foreigner := weightedSum(digits[0:9], 21, 19, 17, 13, 11, 9, 7, 3, 1) % 10
+	other := 11 - weightedSum(digits[0:9], 4, 3, 2, 7, 6, 5, 4, 3, 2)%11
+	if other == 11 {
+		other = 0
+	}
+
+	return person == digits[9] || foreigner == digits[9] || other == digits[9]
+
+}
+
+// checkVATCY checks the check letter of a Cypriot VAT number
+func checkVATCY(number string) bool {
+	oddValues := []int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21}
+	sum := 0
+	for i, d := range digitsOf(number[0:8]) {
+		if i%2 == 0 {
+			d = oddValues[d]
+		}
+		sum += d
+	}
+	return number[8] == byte('A'+sum%26)
+}
+
+// checkVATCZ checks the check digit of a Czech VAT number of a legal entity
+//
+// The 9 and 10 digit numbers of individuals are derived from their birth number and are only checked for their format.
+func checkVATCZ(number string) bool {
+	if len(number) != 8 {
+		return true
+	}
+	digits := digitsOf(number)
+	return (11-weightedSum(digits[0:7], 8, 7, 6, 5, 4, 3, 2)%11)%10 == digits[7]
+}
+
+// checkVATDE checks the ISO 7064 MOD 11,10 check digit of a German VAT number
+func checkVATDE(number string) bool {
+	return iso7064Mod1110(number)
+}
+
+// checkVATDK checks the mod-11 checksum of a Danish VAT number
+func checkVATDK(number string) bool {
+	return weightedSum(digitsOf(number), 2, 7, 6, 5, 4, 3, 2, 1)%11 == 0
+}
+
+// checkVATEE checks the check digit of an Estonian VAT number
+func checkVATEE(number string) bool {
+	digits := digitsOf(number)
+	return (10-weightedSum(digits[0:8], 3, 7, 1, 3, 7, 1, 3, 7)%10)%10 == digits[8]
+}
+
+// checkVATEL checks the check digit of a Greek VAT number
+func checkVATEL(number string) bool {
+	digits := digitsOf(number)
+	return weightedSum(digits[0:8], 256, 128, 64, 32, 16, 8, 4, 2)%11%10 == digits[8]
+}
+
+// checkVATES checks the check character of a Spanish VAT number
+func checkVATES(number string) bool {
+
+	const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+	first := number[0]
+	last := number[8]
+
+	switch {
+
+	case first >= '0' && first <= '9':
+		// Spanish nationals (DNI)
+		n, _ := strconv.Atoi(number[0:8])
+		return last == dniLetters[n%23]
+
+	case first == 'X' || first == 'Y' || first == 'Z':
+		// Foreigners (NIE)
+		n, _ := strconv.Atoi(string('0'+first-'X') + number[1:8])
+		return last == dniLetters[n%23]
+
+	case first == 'K' || first == 'L' || first == 'M':
+		// Spanish nationals without DNI
+		n, _ := strconv.Atoi(number[1:8])
+		return last == dniLetters[n%23]
+
+	}
+
+	// Legal entities
+	sum := 0
+	for i, d := range digitsOf(number[1:8]) {
+		if i%2 == 0 {
+			d *= 2
+		}
+		sum += d/10 + d%10
+	}
+	check := (10 - sum%10) % 10
+
+	digit := last == byte('0'+check)
+	letter := last == "JABCDEFGHI"[check]
+
+	switch {
+	case strings.IndexByte("ABEH", first) >= 0:
+		return digit
+	case strings.IndexByte("CDFGJUV", first) >= 0:
+		return digit || letter
+	case strings.IndexByte("NPQRSW", first) >= 0:
+		return letter
+	}
+
+	return false
+
+}
+
+// checkVATFI checks the check digit of a Finnish VAT number
+func checkVATFI(number string) bool {
+	digits := digitsOf(number)
+	remainder := weightedSum(digits[0:7], 7, 9, 10, 5, 8, 4, 2) % 11
+	if remainder == 1 {
+		return false
+	}
+	return (11-remainder)%11 == digits[7]
+}
+
+// checkVATFR checks the key of a French VAT number
+//
+// Newer keys contain letters, those can only be checked for their format.
+func checkVATFR(number string) bool {
+	if !isDigits(number[0:2]) {
+		return true
+	}
+	key, _ := strconv.Atoi(number[0:2])
+	siren, _ := strconv.Atoi(number[2:])
+	return (12+3*(siren%97))%97 == key
+}
+
+// checkVATHR checks the ISO 7064 MOD 11,10 check digit of a Croatian VAT number
+func checkVATHR(number string) bool {
+	return iso7064Mod1110(number)
+}
+
+// checkVATHU checks the check digit of a Hungarian VAT number
+func checkVATHU(number string) bool {
+	digits := digitsOf(number)
+	return (10-weightedSum(digits[0:7], 9, 7, 3, 1, 9, 7, 3)%10)%10 == digits[7]
+}
+
+// checkVATIE checks the check letter of an Irish VAT number
+func checkVATIE(number string) bool {
+
+	const letters = "WABCDEFGHIJKLMNOPQRSTUV"
+
+	if !isDigits(number[1:2]) {
+		// Old format: the second character is a letter, + or *
+		number = "0" + number[2:7] + number[0:1] + number[7:8]
+	}
+
+	sum := weightedSum(digitsOf(number[0:7]), 8, 7, 6, 5, 4, 3, 2)
+	if len(number) == 9 && number[8] != 'W' {
+		sum += int(number[8]-'A'+1) * 9
+	}
+
+	return number[7] == letters[sum%23]
+
+}
+
+// checkVATIT checks the Luhn check digit of an Italian VAT number
+func checkVATIT(number string) bool {
+	return luhn(number)
+}
+
+// checkVATLT checks the check digit of a Lithuanian VAT number
+func checkVATLT(number string) bool {
+
+	digits := digitsOf(number)
+	body := digits[0 : len(digits)-1]
+
+	check := 0
+	for i, d := range body {
+		check += d * (1 + i%9)
+	}
+	check %= 11
+
+	if check == 10 {
+		check = 0
+		for i, d := range body {
+			check += d * (1 + (i+2)%9)
+		}
+		check = check % 11 % 10
+	}
+
+	return check == digits[len(digits)-1]
+
+}
+
+// checkVATLU checks the check digits of a Luxembourg VAT number
+func checkVATLU(number string) bool {
+	base, _ := strconv.Atoi(number[0:6])
+	check, _ := strconv.Atoi(number[6:8])
+	return base%89 == check
+}
+
+// checkVATLV checks the check digit of a Latvian VAT number of a legal entity
+//
+// The numbers of individuals start with a digit up to 3 and are only checked for their format.
+func checkVATLV(number string) bool {
+
+	digits := digitsOf(number)
+	if digits[0] <= 3 {
+		return true
+	}
+
+	check := 3 - weightedSum(digits[0:10], 9, 1, 4, 8, 3, 10, 2, 5, 7, 6)%11
+	if check == -1 {
+		return false
+	}
+	if check < -1 {
+		check += 11
+	}
+
+	return check == digits[10]
+
+}
+
+// checkVATMT checks the check digits of a Maltese VAT number
+func checkVATMT(number string) bool {
+	check, _ := strconv.Atoi(number[6:8])
+	return 37-weightedSum(digitsOf(number[0:6]), 3, 4, 6, 7, 8, 9)%37 == check
+}
+
+// checkVATNL checks the check digit of a Dutch VAT number
+//
+// Numbers issued to sole proprietors since 2020 use a mod-97 checksum over the full number instead.
+func checkVATNL(number string) bool {
+
+	digits := digitsOf(number[0:9])
+	if weightedSum(digits[0:8], 9, 8, 7, 6, 5, 4, 3, 2)%11 == digits[8] {
+		return true
+	}
+
+	var converted strings.Builder
+	for _, r := range "NL" + number {
+		if r >= 'A' && r <= 'Z' {
+			converted.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			converted.WriteRune(r)
+		}
+	}
+
+	return mod97(converted.String()) == 1
+
+}
+
+// checkVATPL checks the check digit of a Polish VAT number
+func checkVATPL(number string) bool {
+	digits := digitsOf(number)
+	return weightedSum(digits[0:9], 6, 5, 7, 2, 3, 4, 5, 6, 7)%11 == digits[9]
+}
+
+// checkVATPT checks the check digit of a Portuguese VAT number
+func checkVATPT(number string) bool {
+	digits := digitsOf(number)
+	check := 11 - weightedSum(digits[0:8], 9, 8, 7, 6, 5, 4, 3, 2)%11
+	if check > 9 {
+		check = 0
+	}
+	return check == digits[8]
+}
+
+// checkVATRO checks the check digit of a Romanian VAT number
+func checkVATRO(number string) bool {
+	digits := digitsOf(leftPad(number, 10, '0'))
+	check := weightedSum(digits[0:9], 7, 5, 3, 2, 1, 7, 5, 3, 2) * 10 % 11 % 10
+	return check == digits[9]
+}
+
+// checkVATSE checks the Luhn check digit of a Swedish VAT number
+func checkVATSE(number string) bool {
+	return luhn(number[0:10])
+}
+
+// checkVATSI checks the check digit of a Slovenian VAT number
+func checkVATSI(number string) bool {
+	digits := digitsOf(number)
+	check := 11 - weightedSum(digits[0:7], 8, 7, 6, 5, 4, 3, 2)%11
+	if check == 11 {
+		return false
+	}
+	return check%10 == digits[7]
+}
+
+// checkVATSK checks the mod-11 checksum of a Slovak VAT number
+func checkVATSK(number string) bool {
+	n, _ := strconv.ParseInt(number, 10, 64)
+	return n%11 == 0
+}
+
+// checkVATXI checks the check digits of a Northern Irish VAT number
+//
+// Government departments and health authorities don't have check digits.
+func checkVATXI(number string) bool {
+
+	if !isDigits(number) {
+		return true
+	}
+
+	digits := digitsOf(number[0:9])
+	sum := weightedSum(digits[0:7], 8, 7, 6, 5, 4, 3, 2) + digits[7]*10 + digits[8]
+
+	return sum%97 == 0 || (sum+55)%97 == 0
+
+}
+
+// iso7064Mod1110 checks a number of which the last digit is an ISO 7064 MOD 11,10 check digit
+func iso7064Mod1110(number string) bool {
+
+	digits := digitsOf(number)
+
+	product := 10
+	for _, d := range digits[0 : len(digits)-1] {
+		sum := (d + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+
+	return (11-product)%10 == digits[len(digits)-1]
+
+}
+
+// luhn checks a number of which the last digit is a Luhn check digit
+func luhn(number string) bool {
+	digits := digitsOf(number)
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// weightedSum returns the sum of the digits multiplied by their weights
+func weightedSum(digits []int, weights ...int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += digits[i] * w
+	}
+	return sum
+}
+
+// digitsOf converts a string of digits to a slice of ints
+func digitsOf(number string) []int {
+	digits := make([]int, len(number))
+	for i := 0; i < len(number); i++ {
+		digits[i] = int(number[i] - '0')
+	}
+	return digits
+}
+
+// digitAt returns the digit at the given position
+func digitAt(number string, pos int) int {
+	return int(number[pos] - '0')
+}
@@ -0,0 +1,110 @@
+package finance_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func TestValidateVAT(t *testing.T) {
+
+	type test struct {
+		vatNumber     string
+		expectedError error
+	}
+
+	var tests = []test{
+		{"ATU13585627", nil},
+		{"BE0836157420", nil},
+		{"BE 0836.157.420", nil},
+		{"be0836157420", nil},
+		{"BG175074752", nil},
+		{"CY10259033P", nil},
+		{"CZ25123891", nil},
+		{"CZ7103192745", nil},
+		{"DE136695976", nil},
+		{"DK13585628", nil},
+		{"EE100931558", nil},
+		{"EL094259216", nil},
+		{"ESA28015865", nil},
+		{"ESB58378431", nil},
+		{"ESC28015865", nil},
+		{"ESC2801586E", nil},
+		{"ESG58720319", nil},
+		{"ESG5872031I", nil},
+		{"ESU12345674", nil},
+		{"ESU1234567D", nil},
+		{"ESQ2826000H", nil},
+		{"ES12345678Z", nil},
+		{"ESX1234567L", nil},
+		{"FI20774740", nil},
+		{"FR40303265045", nil},
+		{"FRK7399859412", nil},
+		{"HR33392005961", nil},
+		{"HU12892312", nil},
+		{"IE6388047V", nil},
+		{"IE8Z49289F", nil},
+		{"IT00743110157", nil},
+		{"LT119511515", nil},
+		{"LU15027442", nil},
+		{"LV40003521600", nil},
+		{"MT11679112", nil},
+		{"NL004495445B01", nil},
+		{"NL000099998B57", nil},
+		{"PL5260001246", nil},
+		{"PT501964843", nil},
+		{"RO18547290", nil},
+		{"SE556188840401", nil},
+		{"SI50223054", nil},
+		{"SK2022749619", nil},
+		{"XI980780684", nil},
+		{"XIGD001", nil},
+		{"", finance.ErrVATNumberTooShort},
+		{"BE", finance.ErrVATNumberTooShort},
+		{"US123456789", finance.ErrVATUnknownCountry},
+		{"GR094259216", finance.ErrVATUnknownCountry},
+		{"BE08361574", finance.ErrVATInvalidFormat},
+		{"BE2836157420", finance.ErrVATInvalidFormat},
+		{"ATU1358562", finance.ErrVATInvalidFormat},
+		{"DE036695976", finance.ErrVATInvalidFormat},
+		{"NL004495445C01", finance.ErrVATInvalidFormat},
+		{"CY20259033P", finance.ErrVATInvalidFormat},
+		{"ATU13585628", finance.ErrVATInvalidChecksum},
+		{"BE0836157421", finance.ErrVATInvalidChecksum},
+		{"DE136695977", finance.ErrVATInvalidChecksum},
+		{"ESA28015866", finance.ErrVATInvalidChecksum},
+		{"ES12345678A", finance.ErrVATInvalidChecksum},
+		{"ESA2801586E", finance.ErrVATInvalidChecksum},
+		{"ESQ28260008", finance.ErrVATInvalidChecksum},
+		{"ESC2801586F", finance.ErrVATInvalidChecksum},
+		{"FR41303265045", finance.ErrVATInvalidChecksum},
+		{"IE6388047W", finance.ErrVATInvalidChecksum},
+		{"IT00743110158", finance.ErrVATInvalidChecksum},
+		{"NL004495446B01", finance.ErrVATInvalidChecksum},
+		{"PL5260001247", finance.ErrVATInvalidChecksum},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.vatNumber, func(t *testing.T) {
+
+			err := finance.ValidateVAT(tc.vatNumber)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err, "error")
+				return
+			}
+
+			assert.True(t, errors.Is(err, tc.expectedError), "expected %v, got %v", tc.expectedError, err)
+
+			var vatErr *finance.VATError
+			if errors.As(err, &vatErr) {
+				assert.True(t, errors.Is(err, finance.ErrVATnumberNotValid), "not-valid")
+			}
+
+		})
+	}
+
+}
@@ -2,9 +2,8 @@ package finance
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
-	"io/ioutil"
-	"net/http"
 	"strings"
 	"time"
 
@@ -13,17 +12,21 @@ import (
 
 // VATInfo is the info returned about a VAT number
 type VATInfo struct {
-	CountryCode string // The country code
-	VATNumber   string // The VAT number
-	IsValid     bool   // A boolean indicating if the VAT number is valid
-	Name        string // The name linked to the VAT number
-	Address     string // The address linked to the VAT number
+	CountryCode string    // The country code
+	VATNumber   string    // The VAT number
+	IsValid     bool      // A boolean indicating if the VAT number is valid
+	Name        string    // The name linked to the VAT number
+	Address     string    // The address linked to the VAT number
+	CheckedAt   time.Time // The time the VAT number was checked with VIES
+	Stale       bool      // Indicates the info comes from the cache because VIES was unavailable
 }
 
 // DefaultVATServiceURL is the default VAT service URL to use
 const DefaultVATServiceURL = "http://ec.europa.eu/taxation_customs/vies/services/checkVatService"
 
 // VATServiceURL is the SOAP URL to be used when checking a VAT number
+//
+// It's used by the package-level functions. Use NewClient with WithVATServiceURL to configure a client of your own.
 var VATServiceURL = DefaultVATServiceURL
 
 // DefaultVATTimeout is the default timeout to use when checking the VAT service
@@ -46,8 +49,113 @@ var (
 	ErrVATserviceError = "VAT number validation service returns an error: "
 )
 
+// The fault codes returned by the VIES service
+const (
+	VIESFaultInvalidInput               = "INVALID_INPUT"                  // The country code or the VAT number is invalid
+	VIESFaultInvalidRequesterInfo       = "INVALID_REQUESTER_INFO"         // The requester country code or VAT number is invalid
+	VIESFaultServiceUnavailable         = "SERVICE_UNAVAILABLE"            // The VIES service is unavailable
+	VIESFaultMSUnavailable              = "MS_UNAVAILABLE"                 // The service of the member state is unavailable
+	VIESFaultTimeout                    = "TIMEOUT"                        // The service of the member state didn't answer in time
+	VIESFaultVATBlocked                 = "VAT_BLOCKED"                    // The VAT number is blocked
+	VIESFaultIPBlocked                  = "IP_BLOCKED"                     // The IP address of the caller is blocked
+	VIESFaultGlobalMaxConcurrentReq     = "GLOBAL_MAX_CONCURRENT_REQ"      // VIES receives too many requests
+	VIESFaultGlobalMaxConcurrentReqTime = "GLOBAL_MAX_CONCURRENT_REQ_TIME" // VIES receives too many requests
+	VIESFaultMSMaxConcurrentReq         = "MS_MAX_CONCURRENT_REQ"          // The member state receives too many requests
+	VIESFaultMSMaxConcurrentReqTime     = "MS_MAX_CONCURRENT_REQ_TIME"     // The member state receives too many requests
+)
+
+// viesFaultCodes lists the known fault codes and whether they are retryable
+var viesFaultCodes = map[string]bool{
+	VIESFaultInvalidInput:               false,
+	VIESFaultInvalidRequesterInfo:       false,
+	VIESFaultServiceUnavailable:         true,
+	VIESFaultMSUnavailable:              true,
+	VIESFaultTimeout:                    true,
+	VIESFaultVATBlocked:                 false,
+	VIESFaultIPBlocked:                  false,
+	VIESFaultGlobalMaxConcurrentReq:     true,
+	VIESFaultGlobalMaxConcurrentReqTime: true,
+	VIESFaultMSMaxConcurrentReq:         true,
+	VIESFaultMSMaxConcurrentReqTime:     true,
+}
+
+var (
+	// ErrVIESInvalidInput matches the VIES INVALID_INPUT fault when used with errors.Is
+	ErrVIESInvalidInput = &VIESError{Code: VIESFaultInvalidInput, Message: VIESFaultInvalidInput}
+
+	// ErrVIESServiceUnavailable matches the VIES SERVICE_UNAVAILABLE fault when used with errors.Is
+	ErrVIESServiceUnavailable = &VIESError{Code: VIESFaultServiceUnavailable, Message: VIESFaultServiceUnavailable}
+
+	// ErrVIESMSUnavailable matches the VIES MS_UNAVAILABLE fault when used with errors.Is
+	ErrVIESMSUnavailable = &VIESError{Code: VIESFaultMSUnavailable, Message: VIESFaultMSUnavailable}
+
+	// ErrVIESTimeout matches the VIES TIMEOUT fault when used with errors.Is
+	ErrVIESTimeout = &VIESError{Code: VIESFaultTimeout, Message: VIESFaultTimeout}
+
+	// ErrVIESGlobalMaxConcurrentReq matches the VIES GLOBAL_MAX_CONCURRENT_REQ fault when used with errors.Is
+	ErrVIESGlobalMaxConcurrentReq = &VIESError{Code: VIESFaultGlobalMaxConcurrentReq, Message: VIESFaultGlobalMaxConcurrentReq}
+
+	// ErrVIESMSMaxConcurrentReq matches the VIES MS_MAX_CONCURRENT_REQ fault when used with errors.Is
+	ErrVIESMSMaxConcurrentReq = &VIESError{Code: VIESFaultMSMaxConcurrentReq, Message: VIESFaultMSMaxConcurrentReq}
+)
+
+// VIESError is the error returned when the VIES service returns a SOAP fault
+//
+// Use errors.Is with one of the ErrVIES* errors to check for a specific fault. A VIESError with the INVALID_INPUT
+// code also matches ErrVATnumberNotValid.
+type VIESError struct {
+	Code    string // The fault code, one of the VIESFault* constants or empty when the fault is unknown
+	Message string // The fault string as returned by VIES
+}
+
+// Error returns the error message
+func (e *VIESError) Error() string {
+	return ErrVATserviceError + e.Message
+}
+
+// Retryable returns true when the fault is temporary and the request can be retried later
+func (e *VIESError) Retryable() bool {
+	return viesFaultCodes[e.Code]
+}
+
+// Is checks if the target is a VIESError with the same fault code
+func (e *VIESError) Is(target error) bool {
+	if target == ErrVATnumberNotValid {
+		return e.Code == VIESFaultInvalidInput
+	}
+	if t, ok := target.(*VIESError); ok {
+		return e.Code != "" && t.Code == e.Code
+	}
+	return false
+}
+
+// newVIESError creates a VIESError from a SOAP fault string
+func newVIESError(message string) *VIESError {
+	err := &VIESError{Message: message}
+	code := strings.Trim(strings.TrimSpace(message), "{}' ")
+	if _, ok := viesFaultCodes[code]; ok {
+		err.Code = code
+	}
+	return err
+}
+
 // CheckVAT checks the VAT number and returns the data
 func CheckVAT(vatNumber string) (*VATInfo, error) {
+	return defaultClient().CheckVAT(vatNumber)
+}
+
+// CheckVATContext checks the VAT number using the given context and returns the data
+func CheckVATContext(ctx context.Context, vatNumber string) (*VATInfo, error) {
+	return defaultClient().CheckVATContext(ctx, vatNumber)
+}
+
+// CheckVAT checks the VAT number and returns the data
+func (c *Client) CheckVAT(vatNumber string) (*VATInfo, error) {
+	return c.CheckVATContext(context.Background(), vatNumber)
+}
+
+// CheckVATContext checks the VAT number using the given context and returns the data
+func (c *Client) CheckVATContext(ctx context.Context, vatNumber string) (*VATInfo, error) {
 
 	vatNumber = sanitizeVatNumber(vatNumber)
 
@@ -56,28 +164,30 @@ func CheckVAT(vatNumber string) (*VATInfo, error) {
 		return nil, err
 	}
 
-	client := http.Client{
-		Timeout: VATTimeout,
+	if c.vatPrevalidation {
+		if err := ValidateVAT(vatNumber); err != nil {
+			return nil, err
+		}
 	}
 
-	eb := bytes.NewBufferString(e)
-	res, err := client.Post(VATServiceURL, "text/xml;charset=UTF-8", eb)
-	if err != nil {
-		return nil, ErrVATserviceUnreachable
+	if c.vatCache.Cache != nil {
+		return c.checkVATCached(ctx, vatNumber, e)
 	}
-	defer res.Body.Close()
 
-	xmlRes, err := ioutil.ReadAll(res.Body)
+	return c.checkVATRemote(ctx, e)
+
+}
+
+// checkVATRemote performs a checkVat request with VIES
+func (c *Client) checkVATRemote(ctx context.Context, envelope string) (*VATInfo, error) {
+
+	checkedAt := time.Now()
+
+	xmlRes, err := c.performVIESRequest(ctx, envelope)
 	if err != nil {
 		return nil, err
 	}
 
-	if bytes.Contains(xmlRes, []byte("INVALIDINPUT")) {
-		return nil, ErrVATnumberNotValid
-	}
-
-	// return nil, errors.New(string(xmlRes))
-
 	var rd struct {
 		XMLName xml.Name `xml:"Envelope"`
 		Soap    struct {
@@ -102,13 +212,14 @@ func CheckVAT(vatNumber string) (*VATInfo, error) {
 	}
 
 	if rd.Soap.SoapFault.Message != "" {
-		return nil, errors.New(ErrVATserviceError + rd.Soap.SoapFault.Message)
+		return nil, newVIESError(rd.Soap.SoapFault.Message)
 	}
 
 	r := &VATInfo{
 		CountryCode: rd.Soap.Soap.CountryCode,
 		VATNumber:   rd.Soap.Soap.VATnumber,
 		IsValid:     rd.Soap.Soap.Valid,
+		CheckedAt:   checkedAt,
 	}
 
 	if r.IsValid {
@@ -120,6 +231,47 @@ func CheckVAT(vatNumber string) (*VATInfo, error) {
 
 }
 
+// performVIESRequest posts a SOAP envelope to the VIES service and returns the response
+func (c *Client) performVIESRequest(ctx context.Context, envelope string) ([]byte, error) {
+
+	xmlRes, err := c.doRequest(ctx, c.vatTimeout, "POST", c.vatServiceURL, "text/xml;charset=UTF-8", []byte(envelope), retryVIESResponse)
+	if _, ok := err.(*StatusError); ok && parseVIESFault(xmlRes) != "" {
+		err = nil // VIES returns its faults with an HTTP 500 status
+	}
+	if err != nil {
+		switch err.(type) {
+		case *unreachableError, *StatusError:
+			return nil, ErrVATserviceUnreachable
+		}
+		return nil, err
+	}
+
+	if bytes.Contains(xmlRes, []byte("INVALIDINPUT")) {
+		return nil, ErrVATnumberNotValid
+	}
+
+	return xmlRes, nil
+
+}
+
+// parseVIESFault returns the fault string of a SOAP fault, or an empty string when the response isn't a fault
+func parseVIESFault(xmlRes []byte) string {
+	var rd struct {
+		XMLName xml.Name `xml:"Envelope"`
+		Soap    struct {
+			XMLName   xml.Name `xml:"Body"`
+			SoapFault struct {
+				XMLName string `xml:"Fault"`
+				Message string `xml:"faultstring"`
+			}
+		}
+	}
+	if err := xml.Unmarshal(xmlRes, &rd); err != nil {
+		return ""
+	}
+	return rd.Soap.SoapFault.Message
+}
+
 // sanitizeVatNumber removes all white space from a string
 func sanitizeVatNumber(vatNumber string) string {
 	vatNumber = strings.TrimSpace(vatNumber)
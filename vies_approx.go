@@ -0,0 +1,214 @@
+package finance
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// VIESMatch indicates if a trader detail sent with a checkVatApprox request matches the registered data
+type VIESMatch int
+
+// The match indicators returned by the checkVatApprox operation
+const (
+	VIESMatchUnknown      VIESMatch = 0 // VIES didn't return a match indicator
+	VIESMatchValid        VIESMatch = 1 // The detail matches the registered data
+	VIESMatchInvalid      VIESMatch = 2 // The detail doesn't match the registered data
+	VIESMatchNotProcessed VIESMatch = 3 // The member state didn't compare the detail
+)
+
+// VATApproxRequest contains the data sent with a checkVatApprox request
+//
+// The trader details are optional, VIES only returns match indicators for the details which are filled in.
+type VATApproxRequest struct {
+	VATNumber          string // The VAT number to check, including the country code
+	RequesterVATNumber string // The VAT number of the requester, including the country code
+	TraderName         string // The expected name of the trader
+	TraderCompanyType  string // The expected company type of the trader
+	TraderStreet       string // The expected street of the trader
+	TraderPostcode     string // The expected postcode of the trader
+	TraderCity         string // The expected city of the trader
+}
+
+// VATApproxInfo is the info returned by the checkVatApprox operation
+type VATApproxInfo struct {
+	CountryCode            string    // The country code
+	VATNumber              string    // The VAT number
+	IsValid                bool      // A boolean indicating if the VAT number is valid
+	RequestDate            time.Time // The date on which VIES performed the check
+	RequestIdentifier      string    // The consultation number which proves the check was performed
+	TraderName             string    // The name linked to the VAT number
+	TraderCompanyType      string    // The company type linked to the VAT number
+	TraderAddress          string    // The address linked to the VAT number
+	TraderStreet           string    // The street linked to the VAT number
+	TraderPostcode         string    // The postcode linked to the VAT number
+	TraderCity             string    // The city linked to the VAT number
+	TraderNameMatch        VIESMatch // Indicates if the requested trader name matches
+	TraderCompanyTypeMatch VIESMatch // Indicates if the requested company type matches
+	TraderStreetMatch      VIESMatch // Indicates if the requested street matches
+	TraderPostcodeMatch    VIESMatch // Indicates if the requested postcode matches
+	TraderCityMatch        VIESMatch // Indicates if the requested city matches
+}
+
+// String returns a readable representation of the match indicator
+func (m VIESMatch) String() string {
+	switch m {
+	case VIESMatchValid:
+		return "VALID"
+	case VIESMatchInvalid:
+		return "INVALID"
+	case VIESMatchNotProcessed:
+		return "NOT_PROCESSED"
+	}
+	return "UNKNOWN"
+}
+
+// CheckVATApprox checks the VAT number and returns the data, the match indicators and the consultation number
+func CheckVATApprox(request VATApproxRequest) (*VATApproxInfo, error) {
+	return defaultClient().CheckVATApprox(request)
+}
+
+// CheckVATApproxContext checks the VAT number using the given context and returns the data, the match indicators
+// and the consultation number
+func CheckVATApproxContext(ctx context.Context, request VATApproxRequest) (*VATApproxInfo, error) {
+	return defaultClient().CheckVATApproxContext(ctx, request)
+}
+
+// CheckVATApprox checks the VAT number and returns the data, the match indicators and the consultation number
+func (c *Client) CheckVATApprox(request VATApproxRequest) (*VATApproxInfo, error) {
+	return c.CheckVATApproxContext(context.Background(), request)
+}
+
+// CheckVATApproxContext checks the VAT number using the given context and returns the data, the match indicators
+// and the consultation number
+func (c *Client) CheckVATApproxContext(ctx context.Context, request VATApproxRequest) (*VATApproxInfo, error) {
+
+	request.VATNumber = sanitizeVatNumber(request.VATNumber)
+	request.RequesterVATNumber = sanitizeVatNumber(request.RequesterVATNumber)
+
+	e, err := buildApproxEnvelope(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.vatPrevalidation {
+		if err := ValidateVAT(request.VATNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	xmlRes, err := c.performVIESRequest(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var rd struct {
+		XMLName xml.Name `xml:"Envelope"`
+		Soap    struct {
+			XMLName xml.Name `xml:"Body"`
+			Soap    struct {
+				XMLName                xml.Name  `xml:"checkVatApproxResponse"`
+				CountryCode            string    `xml:"countryCode"`
+				VATnumber              string    `xml:"vatNumber"`
+				RequestDate            string    `xml:"requestDate"`
+				Valid                  bool      `xml:"valid"`
+				TraderName             string    `xml:"traderName"`
+				TraderCompanyType      string    `xml:"traderCompanyType"`
+				TraderAddress          string    `xml:"traderAddress"`
+				TraderStreet           string    `xml:"traderStreet"`
+				TraderPostcode         string    `xml:"traderPostcode"`
+				TraderCity             string    `xml:"traderCity"`
+				TraderNameMatch        VIESMatch `xml:"traderNameMatch"`
+				TraderCompanyTypeMatch VIESMatch `xml:"traderCompanyTypeMatch"`
+				TraderStreetMatch      VIESMatch `xml:"traderStreetMatch"`
+				TraderPostcodeMatch    VIESMatch `xml:"traderPostcodeMatch"`
+				TraderCityMatch        VIESMatch `xml:"traderCityMatch"`
+				RequestIdentifier      string    `xml:"requestIdentifier"`
+			}
+			SoapFault struct {
+				XMLName string `xml:"Fault"`
+				Code    string `xml:"faultcode"`
+				Message string `xml:"faultstring"`
+			}
+		}
+	}
+	if err := xml.Unmarshal(xmlRes, &rd); err != nil {
+		return nil, err
+	}
+
+	if rd.Soap.SoapFault.Message != "" {
+		return nil, newVIESError(rd.Soap.SoapFault.Message)
+	}
+
+	resp := rd.Soap.Soap
+
+	r := &VATApproxInfo{
+		CountryCode:            resp.CountryCode,
+		VATNumber:              resp.VATnumber,
+		IsValid:                resp.Valid,
+		RequestIdentifier:      resp.RequestIdentifier,
+		TraderNameMatch:        resp.TraderNameMatch,
+		TraderCompanyTypeMatch: resp.TraderCompanyTypeMatch,
+		TraderStreetMatch:      resp.TraderStreetMatch,
+		TraderPostcodeMatch:    resp.TraderPostcodeMatch,
+		TraderCityMatch:        resp.TraderCityMatch,
+	}
+
+	// The request date is formatted as 2024-01-03+01:00
+	if len(resp.RequestDate) >= len(rateDateLayout) {
+		r.RequestDate, _ = time.Parse(rateDateLayout, resp.RequestDate[0:len(rateDateLayout)])
+	}
+
+	if r.IsValid {
+		r.TraderName = resp.TraderName
+		r.TraderCompanyType = resp.TraderCompanyType
+		r.TraderAddress = resp.TraderAddress
+		r.TraderStreet = resp.TraderStreet
+		r.TraderPostcode = resp.TraderPostcode
+		r.TraderCity = resp.TraderCity
+	}
+
+	return r, nil
+
+}
+
+// buildApproxEnvelope builds the SOAP envelope for a checkVatApprox request
+func buildApproxEnvelope(request VATApproxRequest) (string, error) {
+
+	if len(request.VATNumber) < 3 || len(request.RequesterVATNumber) < 3 {
+		return "", ErrVATNumberTooShort
+	}
+
+	var body bytes.Buffer
+	writeElement := func(name string, value string) {
+		if value == "" {
+			return
+		}
+		body.WriteString("    <" + name + ">")
+		xml.EscapeText(&body, []byte(value))
+		body.WriteString("</" + name + ">\n")
+	}
+
+	writeElement("countryCode", strings.ToUpper(request.VATNumber[0:2]))
+	writeElement("vatNumber", strings.ToUpper(request.VATNumber[2:]))
+	writeElement("traderName", request.TraderName)
+	writeElement("traderCompanyType", request.TraderCompanyType)
+	writeElement("traderStreet", request.TraderStreet)
+	writeElement("traderPostcode", request.TraderPostcode)
+	writeElement("traderCity", request.TraderCity)
+	writeElement("requesterCountryCode", strings.ToUpper(request.RequesterVATNumber[0:2]))
+	writeElement("requesterVatNumber", strings.ToUpper(request.RequesterVATNumber[2:]))
+
+	envelope := `<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+<soapenv:Header/>
+<soapenv:Body>
+  <checkVatApprox xmlns="urn:ec.europa.eu:taxud:vies:services:checkVat:types">
+` + body.String() + `  </checkVatApprox>
+</soapenv:Body>
+</soapenv:Envelope>`
+
+	return envelope, nil
+
+}
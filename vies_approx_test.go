@@ -0,0 +1,101 @@
+package finance_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+const approxResponse = `<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/">
+	<env:Header/>
+	<env:Body>
+		<ns2:checkVatApproxResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types">
+			<ns2:countryCode>BE</ns2:countryCode>
+			<ns2:vatNumber>0836157420</ns2:vatNumber>
+			<ns2:requestDate>2024-01-03+01:00</ns2:requestDate>
+			<ns2:valid>true</ns2:valid>
+			<ns2:traderName>SRL APPLE RETAIL BELGIUM</ns2:traderName>
+			<ns2:traderCompanyType>---</ns2:traderCompanyType>
+			<ns2:traderAddress>Avenue du Port 86C/204
+1000 Bruxelles</ns2:traderAddress>
+			<ns2:traderNameMatch>1</ns2:traderNameMatch>
+			<ns2:traderCityMatch>2</ns2:traderCityMatch>
+			<ns2:requestIdentifier>WAPIAAAAYjH1gRAd</ns2:requestIdentifier>
+		</ns2:checkVatApproxResponse>
+	</env:Body>
+</env:Envelope>`
+
+func TestCheckVATApprox(t *testing.T) {
+
+	var requestBody string
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			requestBody = string(body)
+			w.Write([]byte(approxResponse))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	result, err := client.CheckVATApprox(finance.VATApproxRequest{
+		VATNumber:          "BE 0836.157.420",
+		RequesterVATNumber: "be0123456749",
+		TraderName:         "SRL APPLE RETAIL BELGIUM",
+		TraderCity:         "Brussel & Omgeving",
+	})
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, result, "result") {
+		assert.Equal(t, "BE", result.CountryCode, "country-code")
+		assert.Equal(t, "0836157420", result.VATNumber, "vat-number")
+		assert.True(t, result.IsValid, "is-valid")
+		assert.Equal(t, "2024-01-03", result.RequestDate.Format("2006-01-02"), "request-date")
+		assert.Equal(t, "WAPIAAAAYjH1gRAd", result.RequestIdentifier, "request-identifier")
+		assert.Equal(t, "SRL APPLE RETAIL BELGIUM", result.TraderName, "trader-name")
+		assert.Equal(t, finance.VIESMatchValid, result.TraderNameMatch, "trader-name-match")
+		assert.Equal(t, finance.VIESMatchInvalid, result.TraderCityMatch, "trader-city-match")
+		assert.Equal(t, finance.VIESMatchUnknown, result.TraderStreetMatch, "trader-street-match")
+		assert.Equal(t, "INVALID", result.TraderCityMatch.String(), "trader-city-match-string")
+	}
+
+	assert.Contains(t, requestBody, "<checkVatApprox ", "operation")
+	assert.Contains(t, requestBody, "<vatNumber>0836157420</vatNumber>", "vat-number")
+	assert.Contains(t, requestBody, "<requesterCountryCode>BE</requesterCountryCode>", "requester-country-code")
+	assert.Contains(t, requestBody, "<requesterVatNumber>0123456749</requesterVatNumber>", "requester-vat-number")
+	assert.Contains(t, requestBody, "<traderCity>Brussel &amp; Omgeving</traderCity>", "trader-city")
+	assert.NotContains(t, requestBody, "traderStreet", "trader-street")
+
+}
+
+func TestCheckVATApproxErrors(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>INVALID_REQUESTER_INFO</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	result, err := client.CheckVATApprox(finance.VATApproxRequest{VATNumber: "BE0836157420"})
+
+	assert.Nil(t, result, "result")
+	assert.Equal(t, finance.ErrVATNumberTooShort, err, "too-short")
+
+	result, err = client.CheckVATApprox(finance.VATApproxRequest{VATNumber: "BE0836157420", RequesterVATNumber: "BE0123456749"})
+
+	assert.Nil(t, result, "result")
+	assert.True(t, errors.Is(err, &finance.VIESError{Code: finance.VIESFaultInvalidRequesterInfo}), "fault")
+
+}
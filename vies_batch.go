@@ -0,0 +1,256 @@
+package finance
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultVATBatchWorkers is the default number of concurrent requests when checking a batch of VAT numbers
+const DefaultVATBatchWorkers = 4
+
+// VATBatchOptions defines how a batch of VAT numbers is checked
+type VATBatchOptions struct {
+	Workers          int                       // The number of concurrent requests, defaults to DefaultVATBatchWorkers
+	CountryRateLimit float64                   // The maximum number of requests per second per member state, zero means no limit
+	Ordered          bool                      // Return the results in input order instead of completion order
+	Progress         func(done int, total int) // Called after each check, total is -1 when the input is a channel
+}
+
+// VATBatchResult contains the result of checking one VAT number of a batch
+type VATBatchResult struct {
+	Index int      // The position of the VAT number in the input
+	Input string   // The VAT number as it was passed in
+	Info  *VATInfo // The info returned by VIES, nil when Err is set
+	Err   error    // The error which occurred while checking the VAT number
+}
+
+// vatBatchJob is a VAT number waiting to be checked
+type vatBatchJob struct {
+	index int
+	input string
+}
+
+// countryRateLimiter spaces out the requests for each member state
+type countryRateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// CheckVATBatch checks a list of VAT numbers concurrently and streams back the results
+func CheckVATBatch(ctx context.Context, vatNumbers []string, opts VATBatchOptions) <-chan VATBatchResult {
+	return defaultClient().CheckVATBatch(ctx, vatNumbers, opts)
+}
+
+// CheckVATStream checks the VAT numbers read from a channel concurrently and streams back the results
+func CheckVATStream(ctx context.Context, vatNumbers <-chan string, opts VATBatchOptions) <-chan VATBatchResult {
+	return defaultClient().CheckVATStream(ctx, vatNumbers, opts)
+}
+
+// CheckVATBatch checks a list of VAT numbers concurrently and streams back the results
+//
+// The returned channel is closed once all numbers are checked. When the context is cancelled, the numbers which
+// weren't checked yet are skipped.
+func (c *Client) CheckVATBatch(ctx context.Context, vatNumbers []string, opts VATBatchOptions) <-chan VATBatchResult {
+
+	jobs := make(chan vatBatchJob)
+
+	go func() {
+		defer close(jobs)
+		for i, vatNumber := range vatNumbers {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- vatBatchJob{index: i, input: vatNumber}:
+			}
+		}
+	}()
+
+	return c.checkVATJobs(ctx, jobs, len(vatNumbers), opts)
+
+}
+
+// CheckVATStream checks the VAT numbers read from a channel concurrently and streams back the results
+//
+// The returned channel is closed once the input channel is closed and all numbers are checked. When the context is
+// cancelled, the numbers which weren't checked yet are skipped.
+func (c *Client) CheckVATStream(ctx context.Context, vatNumbers <-chan string, opts VATBatchOptions) <-chan VATBatchResult {
+
+	jobs := make(chan vatBatchJob)
+
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case vatNumber, ok := <-vatNumbers:
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case jobs <- vatBatchJob{index: i, input: vatNumber}:
+				}
+			}
+		}
+	}()
+
+	return c.checkVATJobs(ctx, jobs, -1, opts)
+
+}
+
+// checkVATJobs runs the jobs on a pool of workers and returns the channel with the results
+func (c *Client) checkVATJobs(ctx context.Context, jobs <-chan vatBatchJob, total int, opts VATBatchOptions) <-chan VATBatchResult {
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = DefaultVATBatchWorkers
+	}
+
+	limiter := newCountryRateLimiter(opts.CountryRateLimit)
+
+	completed := make(chan VATBatchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+
+				result := VATBatchResult{Index: job.index, Input: job.input}
+
+				if err := limiter.wait(ctx, vatCountryCode(job.input)); err != nil {
+					result.Err = err
+				} else {
+					result.Info, result.Err = c.CheckVATContext(ctx, job.input)
+				}
+
+				select {
+				case completed <- result:
+				case <-ctx.Done():
+					return
+				}
+
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(completed)
+	}()
+
+	results := make(chan VATBatchResult)
+
+	go func() {
+
+		defer close(results)
+
+		done := 0
+		pending := make(map[int]VATBatchResult)
+		nextIndex := 0
+
+		for result := range completed {
+
+			done++
+			if opts.Progress != nil {
+				opts.Progress(done, total)
+			}
+
+			if !opts.Ordered {
+				if !sendVATBatchResult(ctx, results, result) {
+					return
+				}
+				continue
+			}
+
+			pending[result.Index] = result
+			for {
+				next, ok := pending[nextIndex]
+				if !ok {
+					break
+				}
+				delete(pending, nextIndex)
+				if !sendVATBatchResult(ctx, results, next) {
+					return
+				}
+				nextIndex++
+			}
+
+		}
+
+	}()
+
+	return results
+
+}
+
+// sendVATBatchResult sends a result unless the context is cancelled first, so that the goroutines of a batch exit
+// when the caller stops reading the results after cancelling
+func sendVATBatchResult(ctx context.Context, results chan<- VATBatchResult, result VATBatchResult) bool {
+	select {
+	case results <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// newCountryRateLimiter returns a rate limiter allowing the given number of requests per second per member state
+func newCountryRateLimiter(perSecond float64) *countryRateLimiter {
+	limiter := &countryRateLimiter{
+		next: make(map[string]time.Time),
+	}
+	if perSecond > 0 {
+		limiter.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return limiter
+}
+
+// wait blocks until a request for the given member state is allowed
+func (l *countryRateLimiter) wait(ctx context.Context, country string) error {
+
+	if l.interval == 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[country]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[country] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+
+}
+
+// vatCountryCode returns the country code of a VAT number
+func vatCountryCode(vatNumber string) string {
+	vatNumber = sanitizeVatNumber(vatNumber)
+	if len(vatNumber) < 2 {
+		return ""
+	}
+	return strings.ToUpper(vatNumber[0:2])
+}
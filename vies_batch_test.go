@@ -0,0 +1,242 @@
+package finance_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+var vatNumberElement = regexp.MustCompile(`<countryCode>(\w+)</countryCode>\s*<vatNumber>(\w+)</vatNumber>`)
+
+func newBatchVIESServer(delay func(vatNumber string) time.Duration, requests *int32) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(requests, 1)
+			body, _ := ioutil.ReadAll(r.Body)
+			match := vatNumberElement.FindStringSubmatch(string(body))
+			if delay != nil {
+				time.Sleep(delay(match[2]))
+			}
+			fmt.Fprintf(w, `<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body><ns2:checkVatResponse xmlns:ns2="urn:ec.europa.eu:taxud:vies:services:checkVat:types"><ns2:countryCode>%s</ns2:countryCode><ns2:vatNumber>%s</ns2:vatNumber><ns2:valid>true</ns2:valid><ns2:name>Company %s</ns2:name><ns2:address>---</ns2:address></ns2:checkVatResponse></env:Body></env:Envelope>`, match[1], match[2], match[2])
+		}),
+	)
+}
+
+func TestCheckVATBatchOrdered(t *testing.T) {
+
+	var requests int32
+
+	s := newBatchVIESServer(func(vatNumber string) time.Duration {
+		if vatNumber == "0000000001" {
+			return 100 * time.Millisecond
+		}
+		return 0
+	}, &requests)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	input := []string{"BE0000000001", "BE0000000002", "X", "NL0000000003", "DE0000000004"}
+
+	var progressMu sync.Mutex
+	var progress []int
+
+	results := client.CheckVATBatch(context.Background(), input, finance.VATBatchOptions{
+		Workers: 3,
+		Ordered: true,
+		Progress: func(done int, total int) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			assert.Equal(t, len(input), total, "total")
+			progress = append(progress, done)
+		},
+	})
+
+	index := 0
+	for result := range results {
+
+		assert.Equal(t, index, result.Index, "index")
+		assert.Equal(t, input[index], result.Input, "input")
+
+		if result.Input == "X" {
+			assert.Equal(t, finance.ErrVATNumberTooShort, result.Err, "error")
+			assert.Nil(t, result.Info, "info")
+		} else if assert.NoError(t, result.Err, "error") {
+			assert.Equal(t, "Company "+input[index][2:], result.Info.Name, "name")
+		}
+
+		index++
+
+	}
+
+	assert.Equal(t, len(input), index, "results")
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, progress, "progress")
+	assert.EqualValues(t, 4, atomic.LoadInt32(&requests), "requests")
+
+}
+
+func TestCheckVATBatchCompletionOrder(t *testing.T) {
+
+	var requests int32
+
+	s := newBatchVIESServer(func(vatNumber string) time.Duration {
+		if vatNumber == "0000000001" {
+			return 100 * time.Millisecond
+		}
+		return 0
+	}, &requests)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	var inputs []string
+	for result := range client.CheckVATBatch(context.Background(), []string{"BE0000000001", "BE0000000002"}, finance.VATBatchOptions{Workers: 2}) {
+		inputs = append(inputs, result.Input)
+	}
+
+	assert.Equal(t, []string{"BE0000000002", "BE0000000001"}, inputs, "inputs")
+
+}
+
+func TestCheckVATBatchCountryRateLimit(t *testing.T) {
+
+	var requests int32
+
+	s := newBatchVIESServer(nil, &requests)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	input := []string{"BE0000000001", "BE0000000002", "BE0000000003", "NL0000000004", "DE0000000005"}
+
+	start := time.Now()
+	finished := make(map[string]time.Duration)
+	for result := range client.CheckVATBatch(context.Background(), input, finance.VATBatchOptions{Workers: 5, CountryRateLimit: 10}) {
+		assert.NoError(t, result.Err, "error")
+		finished[result.Input] = time.Since(start)
+	}
+
+	// Any of the Belgian numbers can get the first slot, but the last one can't finish before the third slot
+	var lastBE time.Duration
+	for _, number := range input[0:3] {
+		if finished[number] > lastBE {
+			lastBE = finished[number]
+		}
+	}
+
+	assert.True(t, lastBE >= 200*time.Millisecond, "rate-limited")
+	assert.True(t, finished["NL0000000004"] < 100*time.Millisecond, "other-country")
+	assert.True(t, finished["DE0000000005"] < 100*time.Millisecond, "other-country")
+
+}
+
+func TestCheckVATStream(t *testing.T) {
+
+	var requests int32
+
+	s := newBatchVIESServer(nil, &requests)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	input := make(chan string)
+	go func() {
+		defer close(input)
+		for i := 0; i < 20; i++ {
+			input <- fmt.Sprintf("BE%010d", i)
+		}
+	}()
+
+	var lastTotal int
+	count := 0
+	for result := range client.CheckVATStream(context.Background(), input, finance.VATBatchOptions{
+		Ordered:  true,
+		Progress: func(_ int, total int) { lastTotal = total },
+	}) {
+		assert.Equal(t, fmt.Sprintf("BE%010d", count), result.Input, "input")
+		assert.NoError(t, result.Err, "error")
+		count++
+	}
+
+	assert.Equal(t, 20, count, "count")
+	assert.Equal(t, -1, lastTotal, "total")
+
+}
+
+func TestCheckVATBatchCancelled(t *testing.T) {
+
+	var requests int32
+
+	s := newBatchVIESServer(func(string) time.Duration { return 50 * time.Millisecond }, &requests)
+	defer s.Close()
+
+	client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+	input := make([]string, 100)
+	for i := range input {
+		input[i] = fmt.Sprintf("BE%010d", i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	for range client.CheckVATBatch(ctx, input, finance.VATBatchOptions{Workers: 2}) {
+		count++
+	}
+
+	assert.True(t, count < len(input), "skipped")
+
+}
+
+func TestCheckVATBatchCancelledWithoutDraining(t *testing.T) {
+
+	var requests int32
+
+	s := newBatchVIESServer(func(string) time.Duration { return 5 * time.Millisecond }, &requests)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithHTTPClient(&http.Client{Transport: &http.Transport{DisableKeepAlives: true}}),
+	)
+
+	input := make([]string, 50)
+	for i := range input {
+		input[i] = fmt.Sprintf("BE%010d", i)
+	}
+
+	for _, ordered := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ordered-%v", ordered), func(t *testing.T) {
+
+			before := runtime.NumGoroutine()
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			results := client.CheckVATBatch(ctx, input, finance.VATBatchOptions{Workers: 4, Ordered: ordered})
+			<-results
+			cancel()
+
+			deadline := time.Now().Add(2 * time.Second)
+			for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			assert.True(t, runtime.NumGoroutine() <= before, "goroutines: %d before, %d after", before, runtime.NumGoroutine())
+
+		})
+	}
+
+}
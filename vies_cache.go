@@ -0,0 +1,205 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// VATCacheEntry is a VAT check result stored in a cache
+type VATCacheEntry struct {
+	Info      *VATInfo  // The info returned by VIES
+	CheckedAt time.Time // The time the VAT number was checked with VIES
+}
+
+// VATCache defines a store for VAT check results, keyed by the sanitized VAT number
+//
+// Implementations should be safe for concurrent use.
+type VATCache interface {
+	Get(key string) (*VATCacheEntry, bool, error)
+	Set(key string, entry *VATCacheEntry) error
+}
+
+// VATCachePolicy defines how the results of CheckVAT are cached
+type VATCachePolicy struct {
+	Cache        VATCache      // The cache to store the results in
+	ValidTTL     time.Duration // How long a result for a valid VAT number is served from the cache
+	InvalidTTL   time.Duration // How long a result for an invalid VAT number is served from the cache
+	StaleIfError bool          // Return an expired result when VIES is unavailable, with Stale set to true
+	OnError      func(error)   // Called when the cache can't be read or written, the check itself goes on without it
+}
+
+// MemoryVATCache is a VATCache which keeps the results in memory
+type MemoryVATCache struct {
+	mu      sync.RWMutex
+	entries map[string]*VATCacheEntry
+}
+
+// FileVATCache is a VATCache which stores each result as a JSON file in a directory
+type FileVATCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewMemoryVATCache returns a new, empty in-memory cache
+func NewMemoryVATCache() *MemoryVATCache {
+	return &MemoryVATCache{
+		entries: make(map[string]*VATCacheEntry),
+	}
+}
+
+// NewFileVATCache returns a cache which stores its entries in the given directory, creating it when needed
+func NewFileVATCache(dir string) (*FileVATCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileVATCache{dir: dir}, nil
+}
+
+// Get returns the entry for the given key
+func (m *MemoryVATCache) Get(key string) (*VATCacheEntry, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, ok := m.entries[key]
+	return entry, ok, nil
+}
+
+// Set stores the entry for the given key
+func (m *MemoryVATCache) Set(key string, entry *VATCacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = entry
+	return nil
+}
+
+// Get returns the entry for the given key
+func (f *FileVATCache) Get(key string) (*VATCacheEntry, bool, error) {
+
+	rawData, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var entry VATCacheEntry
+	if err := json.Unmarshal(rawData, &entry); err != nil {
+		return nil, false, err
+	}
+
+	return &entry, true, nil
+
+}
+
+// Set stores the entry for the given key
+//
+// The entry is written to a temporary file first so that readers never see a partially written entry.
+func (f *FileVATCache) Set(key string, entry *VATCacheEntry) error {
+
+	rawData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := ioutil.TempFile(f.dir, ".vat-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(rawData); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path(key))
+
+}
+
+// path returns the file in which the entry for a key is stored
+func (f *FileVATCache) path(key string) string {
+	return filepath.Join(f.dir, url.PathEscape(key)+".json")
+}
+
+// checkVATCached checks a VAT number, serving the result from the cache when possible
+func (c *Client) checkVATCached(ctx context.Context, vatNumber string, envelope string) (*VATInfo, error) {
+
+	policy := c.vatCache
+	key := vatCacheKey(vatNumber)
+
+	entry, found, err := policy.Cache.Get(key)
+	if err != nil {
+		policy.reportError(errors.Wrap(err, "VAT cache get "+key))
+	}
+	if found && entry.Info != nil && policy.isFresh(entry) {
+		info := *entry.Info
+		info.CheckedAt = entry.CheckedAt
+		return &info, nil
+	}
+
+	info, err := c.checkVATRemote(ctx, envelope)
+	if err == nil {
+		stored := *info
+		if err := policy.Cache.Set(key, &VATCacheEntry{Info: &stored, CheckedAt: info.CheckedAt}); err != nil {
+			policy.reportError(errors.Wrap(err, "VAT cache set "+key))
+		}
+		return info, nil
+	}
+
+	if policy.StaleIfError && found && entry.Info != nil && isVIESUnavailable(err) {
+		info := *entry.Info
+		info.CheckedAt = entry.CheckedAt
+		info.Stale = true
+		return &info, nil
+	}
+
+	return nil, err
+
+}
+
+// isFresh returns true when the entry can still be served from the cache
+func (p VATCachePolicy) isFresh(entry *VATCacheEntry) bool {
+	ttl := p.InvalidTTL
+	if entry.Info.IsValid {
+		ttl = p.ValidTTL
+	}
+	return time.Since(entry.CheckedAt) < ttl
+}
+
+// reportError passes an error of the cache to OnError, when set
+func (p VATCachePolicy) reportError(err error) {
+	if p.OnError != nil {
+		p.OnError(err)
+	}
+}
+
+// isVIESUnavailable returns true when the error indicates that VIES or the member state can't answer right now
+func isVIESUnavailable(err error) bool {
+	if err == ErrVATserviceUnreachable {
+		return true
+	}
+	if viesErr, ok := err.(*VIESError); ok {
+		return viesErr.Retryable()
+	}
+	return false
+}
+
+// vatCacheKey returns the key under which the result for a sanitized VAT number is cached
+func vatCacheKey(vatNumber string) string {
+	return strings.ToUpper(vatNumber)
+}
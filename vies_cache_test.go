@@ -0,0 +1,281 @@
+package finance_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pieterclaerhout/go-finance"
+)
+
+func newCachingVIESServer(attempts *int32, down *int32) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(attempts, 1)
+			if atomic.LoadInt32(down) == 1 {
+				writeVIESFault(w, finance.VIESFaultMSUnavailable)
+				return
+			}
+			w.Write([]byte(checkVatResponse))
+		}),
+	)
+}
+
+func TestCheckVATCache(t *testing.T) {
+
+	var attempts, down int32
+	s := newCachingVIESServer(&attempts, &down)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithVATCache(finance.VATCachePolicy{
+			Cache:    finance.NewMemoryVATCache(),
+			ValidTTL: time.Hour,
+		}),
+	)
+
+	first, err := client.CheckVAT("BE 0836.157.420")
+	assert.NoError(t, err, "first-error")
+
+	second, err := client.CheckVAT("be0836157420")
+	assert.NoError(t, err, "second-error")
+
+	if assert.NotNil(t, first, "first") && assert.NotNil(t, second, "second") {
+		assert.Equal(t, nameApple, second.Name, "name")
+		assert.Equal(t, first.CheckedAt, second.CheckedAt, "checked-at")
+		assert.False(t, second.Stale, "stale")
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestCheckVATCacheExpired(t *testing.T) {
+
+	var attempts, down int32
+	s := newCachingVIESServer(&attempts, &down)
+	defer s.Close()
+
+	cache := finance.NewMemoryVATCache()
+	cache.Set("BE0836157420", &finance.VATCacheEntry{
+		Info:      &finance.VATInfo{CountryCode: "BE", VATNumber: "0836157420", IsValid: true, Name: "OLD NAME"},
+		CheckedAt: time.Now().Add(-2 * time.Hour),
+	})
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithVATCache(finance.VATCachePolicy{Cache: cache, ValidTTL: time.Hour}),
+	)
+
+	result, err := client.CheckVAT("BE0836157420")
+
+	assert.NoError(t, err, "error")
+	if assert.NotNil(t, result, "result") {
+		assert.Equal(t, nameApple, result.Name, "name")
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "attempts")
+
+	entry, found, err := cache.Get("BE0836157420")
+	assert.NoError(t, err, "get-error")
+	if assert.True(t, found, "found") {
+		assert.Equal(t, nameApple, entry.Info.Name, "cached-name")
+	}
+
+}
+
+func TestCheckVATCacheStaleIfError(t *testing.T) {
+
+	var attempts int32
+	down := int32(1)
+	s := newCachingVIESServer(&attempts, &down)
+	defer s.Close()
+
+	checkedAt := time.Now().Add(-48 * time.Hour)
+
+	cache := finance.NewMemoryVATCache()
+	cache.Set("BE0836157420", &finance.VATCacheEntry{
+		Info:      &finance.VATInfo{CountryCode: "BE", VATNumber: "0836157420", IsValid: true, Name: nameApple},
+		CheckedAt: checkedAt,
+	})
+
+	type test struct {
+		name         string
+		staleIfError bool
+	}
+
+	var tests = []test{
+		{"stale-if-error", true},
+		{"no-stale-if-error", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			client := finance.NewClient(
+				finance.WithVATServiceURL(s.URL),
+				finance.WithVATCache(finance.VATCachePolicy{
+					Cache:        cache,
+					ValidTTL:     time.Hour,
+					StaleIfError: tc.staleIfError,
+				}),
+			)
+
+			result, err := client.CheckVAT("BE0836157420")
+
+			if !tc.staleIfError {
+				assert.Error(t, err, "error")
+				assert.Nil(t, result, "result")
+				return
+			}
+
+			assert.NoError(t, err, "error")
+			if assert.NotNil(t, result, "result") {
+				assert.True(t, result.Stale, "stale")
+				assert.Equal(t, nameApple, result.Name, "name")
+				assert.True(t, checkedAt.Equal(result.CheckedAt), "checked-at")
+			}
+
+		})
+	}
+
+}
+
+func TestCheckVATCacheInvalidTTL(t *testing.T) {
+
+	var attempts, down int32
+	s := newCachingVIESServer(&attempts, &down)
+	defer s.Close()
+
+	cache := finance.NewMemoryVATCache()
+	cache.Set("BE0123456789", &finance.VATCacheEntry{
+		Info:      &finance.VATInfo{CountryCode: "BE", VATNumber: "0123456789"},
+		CheckedAt: time.Now().Add(-10 * time.Minute),
+	})
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithVATCache(finance.VATCachePolicy{
+			Cache:      cache,
+			ValidTTL:   time.Hour,
+			InvalidTTL: 5 * time.Minute,
+		}),
+	)
+
+	_, err := client.CheckVAT("BE0123456789")
+	assert.NoError(t, err, "error")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+func TestFileVATCache(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "vatcache")
+	if !assert.NoError(t, err, "tempdir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := finance.NewFileVATCache(dir)
+	if !assert.NoError(t, err, "new-cache") {
+		return
+	}
+
+	_, found, err := cache.Get("BE0836157420")
+	assert.NoError(t, err, "missing-error")
+	assert.False(t, found, "missing-found")
+
+	checkedAt := time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)
+	err = cache.Set("BE0836157420", &finance.VATCacheEntry{
+		Info:      &finance.VATInfo{CountryCode: "BE", VATNumber: "0836157420", IsValid: true, Name: nameApple, Address: addrApple},
+		CheckedAt: checkedAt,
+	})
+	assert.NoError(t, err, "set-error")
+
+	reopened, err := finance.NewFileVATCache(dir)
+	if !assert.NoError(t, err, "reopen") {
+		return
+	}
+
+	entry, found, err := reopened.Get("BE0836157420")
+	assert.NoError(t, err, "get-error")
+	if assert.True(t, found, "found") {
+		assert.Equal(t, nameApple, entry.Info.Name, "name")
+		assert.Equal(t, addrApple, entry.Info.Address, "address")
+		assert.True(t, checkedAt.Equal(entry.CheckedAt), "checked-at")
+	}
+
+}
+
+func TestCheckVATCacheStoresCopy(t *testing.T) {
+
+	var attempts, down int32
+	s := newCachingVIESServer(&attempts, &down)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithVATCache(finance.VATCachePolicy{
+			Cache:    finance.NewMemoryVATCache(),
+			ValidTTL: time.Hour,
+		}),
+	)
+
+	first, err := client.CheckVAT("BE0836157420")
+	if !assert.NoError(t, err, "first-error") {
+		return
+	}
+	first.Name = "CHANGED BY THE CALLER"
+
+	second, err := client.CheckVAT("BE0836157420")
+	if assert.NoError(t, err, "second-error") {
+		assert.Equal(t, nameApple, second.Name, "name")
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "attempts")
+
+}
+
+// brokenVATCache is a VATCache which can't be read or written
+type brokenVATCache struct{}
+
+func (brokenVATCache) Get(key string) (*finance.VATCacheEntry, bool, error) {
+	return nil, false, errors.New("cache unavailable")
+}
+
+func (brokenVATCache) Set(key string, entry *finance.VATCacheEntry) error {
+	return errors.New("cache unavailable")
+}
+
+func TestCheckVATCacheErrors(t *testing.T) {
+
+	var attempts, down int32
+	s := newCachingVIESServer(&attempts, &down)
+	defer s.Close()
+
+	var cacheErrors []string
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithVATCache(finance.VATCachePolicy{
+			Cache:    brokenVATCache{},
+			ValidTTL: time.Hour,
+			OnError: func(err error) {
+				cacheErrors = append(cacheErrors, err.Error())
+			},
+		}),
+	)
+
+	info, err := client.CheckVAT("BE0836157420")
+	assert.NoError(t, err, "error")
+	assert.NotNil(t, info, "info")
+	assert.Equal(t, []string{
+		"VAT cache get BE0836157420: cache unavailable",
+		"VAT cache set BE0836157420: cache unavailable",
+	}, cacheErrors, "cache-errors")
+
+}
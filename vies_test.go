@@ -1,6 +1,8 @@
 package finance_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -9,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pieterclaerhout/go-finance"
+	"github.com/pieterclaerhout/go-finance/financetest"
 )
 
 const nameApple = "SRL APPLE RETAIL BELGIUM"
@@ -35,11 +38,20 @@ func TestCheck(t *testing.T) {
 		{"valid-ie", "IE6388047V", "IE", "6388047V", "GOOGLE IRELAND LIMITED", "3RD FLOOR, GORDON HOUSE, BARROW STREET, DUBLIN 4", true, nil},
 	}
 
+	s := financetest.NewVIESServer(
+		financetest.VATRecord{CountryCode: "BE", VATNumber: "0836157420", Valid: true, Name: nameApple, Address: addrApple},
+		financetest.VATRecord{CountryCode: "IE", VATNumber: "6388047V", Valid: true, Name: "GOOGLE IRELAND LIMITED", Address: "3RD FLOOR, GORDON HOUSE, BARROW STREET, DUBLIN 4"},
+	)
+	defer s.Close()
+
+	finance.VATServiceURL = s.URL
+	defer func() {
+		finance.VATServiceURL = finance.DefaultVATServiceURL
+	}()
+
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 
-			time.Sleep(1 * time.Second)
-
 			result, err := finance.CheckVAT(tc.vatNumber)
 
 			if tc.expectedError != nil {
@@ -191,3 +203,117 @@ func TestCheckVATSoapFault(t *testing.T) {
 	assert.Equal(t, finance.ErrVATserviceError+"error", err.Error(), "error-message")
 
 }
+
+func TestCheckVATContextCancelled(t *testing.T) {
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(500 * time.Millisecond)
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte("hello"))
+		}),
+	)
+	defer s.Close()
+
+	finance.VATServiceURL = s.URL
+	defer func() {
+		finance.VATServiceURL = finance.DefaultVATServiceURL
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := finance.CheckVATContext(ctx, "BE0836157420")
+
+	assert.Nil(t, result)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+}
+
+func TestCheckVATPrevalidation(t *testing.T) {
+
+	var requests int
+
+	s := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+			w.Write([]byte("INVALIDINPUT"))
+		}),
+	)
+	defer s.Close()
+
+	client := finance.NewClient(
+		finance.WithVATServiceURL(s.URL),
+		finance.WithVATPrevalidation(true),
+	)
+
+	result, err := client.CheckVAT("BE0836157421")
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, finance.ErrVATInvalidChecksum))
+	assert.True(t, errors.Is(err, finance.ErrVATnumberNotValid))
+	assert.Zero(t, requests)
+
+	result, err = client.CheckVAT("BE0836157420")
+
+	assert.Nil(t, result)
+	assert.Equal(t, finance.ErrVATnumberNotValid, err)
+	assert.Equal(t, 1, requests)
+
+}
+
+func TestCheckVATVIESFaults(t *testing.T) {
+
+	type test struct {
+		faultString       string
+		expectedCode      string
+		expectedError     error
+		expectedRetryable bool
+	}
+
+	var tests = []test{
+		{"INVALID_INPUT", finance.VIESFaultInvalidInput, finance.ErrVIESInvalidInput, false},
+		{"SERVICE_UNAVAILABLE", finance.VIESFaultServiceUnavailable, finance.ErrVIESServiceUnavailable, true},
+		{"MS_UNAVAILABLE", finance.VIESFaultMSUnavailable, finance.ErrVIESMSUnavailable, true},
+		{"TIMEOUT", finance.VIESFaultTimeout, finance.ErrVIESTimeout, true},
+		{"GLOBAL_MAX_CONCURRENT_REQ", finance.VIESFaultGlobalMaxConcurrentReq, finance.ErrVIESGlobalMaxConcurrentReq, true},
+		{"MS_MAX_CONCURRENT_REQ", finance.VIESFaultMSMaxConcurrentReq, finance.ErrVIESMSMaxConcurrentReq, true},
+		{"{ 'MS_UNAVAILABLE' }", finance.VIESFaultMSUnavailable, finance.ErrVIESMSUnavailable, true},
+		{"Something else", "", nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.faultString, func(t *testing.T) {
+
+			s := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultcode>soap:Server</faultcode><faultstring>` + tc.faultString + `</faultstring></soap:Fault></soap:Body></soap:Envelope>`))
+				}),
+			)
+			defer s.Close()
+
+			client := finance.NewClient(finance.WithVATServiceURL(s.URL))
+
+			result, err := client.CheckVAT("BE0836157420")
+
+			assert.Nil(t, result)
+
+			var viesErr *finance.VIESError
+			if assert.True(t, errors.As(err, &viesErr)) {
+				assert.Equal(t, tc.expectedCode, viesErr.Code, "code")
+				assert.Equal(t, tc.faultString, viesErr.Message, "message")
+				assert.Equal(t, tc.expectedRetryable, viesErr.Retryable(), "retryable")
+				assert.Equal(t, finance.ErrVATserviceError+tc.faultString, err.Error(), "error-message")
+			}
+
+			if tc.expectedError != nil {
+				assert.True(t, errors.Is(err, tc.expectedError), "is")
+			}
+			assert.False(t, errors.Is(err, &finance.VIESError{Code: finance.VIESFaultIPBlocked}), "not-ip-blocked")
+			assert.Equal(t, tc.expectedCode == finance.VIESFaultInvalidInput, errors.Is(err, finance.ErrVATnumberNotValid), "not-valid")
+
+		})
+	}
+
+}